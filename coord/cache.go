@@ -0,0 +1,120 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Cache of Locations, keyed by the normalized address.
+type Cache interface {
+	Get(key string) (Location, bool)
+	Set(key string, loc Location)
+}
+
+// Cached returns a Geocoder which asks g only for addresses not found in the cache.
+//
+// A cache hit does not touch g at all, so it does not consume its rate limit;
+// it is counted by the Metrics of g, if it is a *Client.
+func Cached(g Geocoder, cache Cache) Geocoder {
+	return cachedGeocoder{Geocoder: g, cache: cache}
+}
+
+type cachedGeocoder struct {
+	Geocoder
+	cache Cache
+}
+
+func (cg cachedGeocoder) Get(ctx context.Context, address string) (Location, error) {
+	key := NormalizeAddress(address)
+	if loc, ok := cg.cache.Get(key); ok {
+		if mc, ok := cg.Geocoder.(metricsCarrier); ok {
+			if m := mc.metrics(); m != nil {
+				m.IncCacheHit()
+			}
+		}
+		return loc, nil
+	}
+	loc, err := cg.Geocoder.Get(ctx, address)
+	if err != nil {
+		return loc, err
+	}
+	cg.cache.Set(key, loc)
+	return loc, nil
+}
+
+// NormalizeAddress returns the canonical form of the address:
+// Unicode NFC, case folded, with the whitespace collapsed.
+func NormalizeAddress(address string) string {
+	return addressFolder.String(norm.NFC.String(strings.Join(strings.Fields(address), " ")))
+}
+
+var addressFolder = cases.Fold()
+
+// NewLRU returns an in-memory Cache holding at most size Locations,
+// evicting the least recently used one.
+func NewLRU(size int) Cache {
+	if size <= 0 {
+		size = 1024
+	}
+	return &lruCache{size: size, m: make(map[string]*list.Element, size)}
+}
+
+type lruCache struct {
+	m    map[string]*list.Element
+	l    list.List
+	size int
+	mu   sync.Mutex
+}
+
+type lruEntry struct {
+	key string
+	loc Location
+}
+
+func (c *lruCache) Get(key string) (Location, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.m[key]
+	if !ok {
+		return Location{}, false
+	}
+	c.l.MoveToFront(e)
+	return e.Value.(*lruEntry).loc, true
+}
+
+func (c *lruCache) Set(key string, loc Location) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.m[key]; ok {
+		e.Value.(*lruEntry).loc = loc
+		c.l.MoveToFront(e)
+		return
+	}
+	c.m[key] = c.l.PushFront(&lruEntry{key: key, loc: loc})
+	for c.l.Len() > c.size {
+		e := c.l.Back()
+		c.l.Remove(e)
+		delete(c.m, e.Value.(*lruEntry).key)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"testing"
+)
+
+type countingGeocoder struct {
+	n int
+}
+
+func (cg *countingGeocoder) Get(ctx context.Context, address string) (Location, error) {
+	cg.n++
+	return Location{Address: address, Lat: float64(cg.n)}, nil
+}
+
+func TestCached(t *testing.T) {
+	var cg countingGeocoder
+	g := Cached(&cg, NewLRU(2))
+	ctx := context.Background()
+	for _, a := range []string{"Budapest", " budapest ", "BUDAPEST"} {
+		if _, err := g.Get(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cg.n != 1 {
+		t.Errorf("got %d calls, wanted 1", cg.n)
+	}
+	for _, a := range []string{"Debrecen", "Szeged", "Budapest"} {
+		if _, err := g.Get(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cg.n != 4 {
+		t.Errorf("got %d calls, wanted 4 (evicted)", cg.n)
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	want := NormalizeAddress("1600 Amphitheatre Pkwy")
+	for _, a := range []string{"1600  amphitheatre pkwy ", "\t1600 AMPHITHEATRE\nPkwy"} {
+		if got := NormalizeAddress(a); got != want {
+			t.Errorf("%q: got %q, wanted %q", a, got, want)
+		}
+	}
+	// NFD vs NFC, and case
+	if a, b := NormalizeAddress("Gyo\u030br"), NormalizeAddress("GYŐR"); a != b {
+		t.Errorf("got %q and %q", a, b)
+	}
+}
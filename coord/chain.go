@@ -0,0 +1,43 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"errors"
+)
+
+// Chain returns a Geocoder which asks the geocoders in order,
+// and returns the first hit.
+//
+// It moves on to the next geocoder only on ErrNotFound,
+// any other error (such as ErrRequestDenied or ErrOverQuota) is returned as is.
+func Chain(geocoders ...Geocoder) Geocoder {
+	return chainGeocoder(geocoders)
+}
+
+type chainGeocoder []Geocoder
+
+func (gs chainGeocoder) Get(ctx context.Context, address string) (Location, error) {
+	for _, g := range gs {
+		loc, err := g.Get(ctx, address)
+		if err == nil || !errors.Is(err, ErrNotFound) {
+			return loc, err
+		}
+	}
+	return Location{}, ErrNotFound
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type errGeocoder struct {
+	err error
+	n   int
+}
+
+func (eg *errGeocoder) Get(ctx context.Context, address string) (Location, error) {
+	eg.n++
+	return Location{}, eg.err
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	notFound, denied := &errGeocoder{err: ErrNotFound}, &errGeocoder{err: ErrRequestDenied}
+	var cg countingGeocoder
+	loc, err := Chain(notFound, &cg, denied).Get(ctx, "Budapest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Address != "Budapest" || notFound.n != 1 || cg.n != 1 || denied.n != 0 {
+		t.Errorf("got %#v, calls: %d %d %d", loc, notFound.n, cg.n, denied.n)
+	}
+
+	if _, err = Chain(denied, &cg).Get(ctx, "Budapest"); !errors.Is(err, ErrRequestDenied) {
+		t.Errorf("got %v, wanted ErrRequestDenied", err)
+	}
+	if cg.n != 1 {
+		t.Errorf("got %d calls after a denial, wanted none", cg.n-1)
+	}
+
+	if _, err = Chain(notFound, notFound).Get(ctx, "Budapest"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v, wanted ErrNotFound", err)
+	}
+}
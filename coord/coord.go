@@ -15,132 +15,725 @@ limitations under the License.
 */
 
 // Package coord contains a function to get the coordinates of
-// a human-readable address, using GMaps.
+// a human-readable address, using GMaps (or Mapbox).
 package coord
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rogpeppe/retry"
 	"golang.org/x/time/rate"
 )
 
-const gmapsURL = `https://maps.googleapis.com/maps/api/geocode/json?key={{.APIKey}}&sensors=false&address={{.Address}}`
+// DefaultBaseURL is the base URL of the Google Maps APIs, see Client.BaseURL.
+const DefaultBaseURL = "https://maps.googleapis.com/maps/api"
+
+const geocodePath = "/geocode/json"
 
 var (
 	ErrNotFound       = errors.New("not found")
 	ErrTooManyResults = errors.New("too many results")
 
+	// ErrOverQuota is returned for OVER_QUERY_LIMIT and OVER_DAILY_LIMIT statuses:
+	// back off, or check the billing.
+	ErrOverQuota = errors.New("over quota")
+	// ErrRequestDenied is returned for the REQUEST_DENIED status, mostly for an invalid API key.
+	ErrRequestDenied = errors.New("request denied")
+	// ErrInvalidRequest is returned for the INVALID_REQUEST status: fix the request.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrUnknown is returned for the UNKNOWN_ERROR status: a retry may succeed.
+	ErrUnknown = errors.New("unknown error")
+	// ErrMissingAPIKey is returned before any request, if the API key (or access token) is empty.
+	ErrMissingAPIKey = errors.New("missing API key")
+
 	gmapsRateLimit = rate.NewLimiter(1, 1)
 
-	// APIKey is the API_KEY served too Google Maps services.
-	// It is set by default to the contents of the GOOGLE_MAPS_API_KEY env var.
-	APIKey = os.Getenv("GOOGLE_MAPS_API_KEY")
+	// DefaultClient is the Client used by Get.
+	// Its APIKey is set to the contents of the GOOGLE_MAPS_API_KEY env var.
+	DefaultClient = NewClientFromEnv()
+
+	// DefaultLanguage is used for the requests without Options.Language,
+	// set from the GOOGLE_MAPS_LANGUAGE env var.
+	DefaultLanguage = os.Getenv("GOOGLE_MAPS_LANGUAGE")
+	// DefaultRegion is used for the requests without Options.Region,
+	// set from the GOOGLE_MAPS_REGION env var.
+	DefaultRegion = os.Getenv("GOOGLE_MAPS_REGION")
 )
 
+// Geocoder returns the Location of a human-readable address.
+type Geocoder interface {
+	Get(ctx context.Context, address string) (Location, error)
+}
+
+// Client is a Google Maps geocoding client.
+type Client struct {
+	// Options are used by Get.
+	Options Options
+	// RateLimit limits the requests of this Client.
+	// If nil, a package-level limiter (1 request per second) is shared
+	// by all such Clients.
+	RateLimit *rate.Limiter
+	// APIKey is the API_KEY served to Google Maps services.
+	APIKey string
+	// Adaptive makes the Client to adjust its RateLimit by the responses:
+	// halve it on OVER_QUERY_LIMIT, raise it a bit on success,
+	// between MinLimit and MaxLimit.
+	// The shared package-level limiter is never adjusted.
+	Adaptive bool
+	// MinLimit and MaxLimit bound the Adaptive adjustments;
+	// DefaultMinLimit and DefaultMaxLimit if zero.
+	MinLimit, MaxLimit rate.Limit
+	// OnRequest is called after each HTTP request (attempt), if not nil.
+	OnRequest func(RequestInfo)
+	// Timeout bounds each request, including the rate limit waits,
+	// the HTTP round trips and the retries, if positive.
+	// The returned error wraps context.DeadlineExceeded when it expires.
+	Timeout time.Duration
+	// KeepRaw keeps the raw JSON response of the geocoding requests, for debugging:
+	// in Location.Raw, and in a *RawError for the errors with a response.
+	KeepRaw bool
+	// Retry is the retry strategy of the requests; DefaultRetryStrategy if nil.
+	// Its MaxDuration also limits the wait for a Retry-After.
+	Retry *retry.Strategy
+	// Metrics receives the aggregate counts and latencies, if not nil.
+	Metrics Metrics
+	// BaseURL of the Google Maps APIs (such as a mirroring proxy, or a test server);
+	// DefaultBaseURL if empty.
+	BaseURL string
+	// HTTPClient does the requests; http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimSuffix(c.BaseURL, "/")
+}
+
+// redactURL returns the URL with the values of its key and access_token parameters replaced,
+// so it can be logged and returned in errors.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "(unparseable URL)"
+	}
+	q := u.Query()
+	for _, k := range []string{"key", "access_token"} {
+		if q.Has(k) {
+			q.Set(k, "REDACTED")
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// redactError redacts the URL of the *url.Error (as returned by http.Client.Do) in err.
+func redactError(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		ue.URL = redactURL(ue.URL)
+	}
+	return err
+}
+
+// checkAPIKey returns ErrMissingAPIKey if the APIKey is empty.
+func (c *Client) checkAPIKey() error {
+	if c.APIKey == "" {
+		return fmt.Errorf("Google Maps: %w (set GOOGLE_MAPS_API_KEY)", ErrMissingAPIKey)
+	}
+	return nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+const (
+	// DefaultMinLimit is the lowest limit (1 request per 10s) an Adaptive Client lowers its RateLimit to.
+	DefaultMinLimit = rate.Limit(0.1)
+	// DefaultMaxLimit is the highest limit an Adaptive Client raises its RateLimit to
+	// (the default per-second quota of the Geocoding API).
+	DefaultMaxLimit = rate.Limit(50)
+)
+
+// adaptMu serializes the Adaptive adjustments, as the limiters may be shared between Clients.
+var adaptMu sync.Mutex
+
+// adapt adjusts the RateLimit: halves it on overQuota, raises it a bit otherwise,
+// clamped between MinLimit and MaxLimit.
+//
+// A limit already above MaxLimit (such as rate.Inf) is not raised, just lowered to MaxLimit on overQuota.
+func (c *Client) adapt(overQuota bool) {
+	minLimit, maxLimit := c.MinLimit, c.MaxLimit
+	if minLimit <= 0 {
+		minLimit = DefaultMinLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxLimit
+	}
+	if maxLimit < minLimit {
+		maxLimit = minLimit
+	}
+	adaptMu.Lock()
+	defer adaptMu.Unlock()
+	limit := c.RateLimit.Limit()
+	if overQuota {
+		if limit > maxLimit {
+			limit = maxLimit
+		} else if limit /= 2; limit < minLimit {
+			limit = minLimit
+		}
+	} else if limit < maxLimit {
+		if limit *= 1.1; limit > maxLimit {
+			limit = maxLimit
+		} else if limit < minLimit {
+			limit = minLimit
+		}
+	}
+	if limit != c.RateLimit.Limit() {
+		c.RateLimit.SetLimit(limit)
+	}
+}
+
+// retryStrategy returns r, or DefaultRetryStrategy if nil.
+func retryStrategy(r *retry.Strategy) *retry.Strategy {
+	if r != nil {
+		return r
+	}
+	return &DefaultRetryStrategy
+}
+
+// RawError is an error with the raw response, see Client.KeepRaw.
+type RawError struct {
+	Err error
+	Raw json.RawMessage
+}
+
+func (re *RawError) Error() string { return re.Err.Error() + ": " + string(re.Raw) }
+func (re *RawError) Unwrap() error { return re.Err }
+
+// RequestInfo describes one request (attempt) to the Google Maps API, for OnRequest.
+type RequestInfo struct {
+	// Err is the error of this attempt.
+	Err error
+	// Address is the requested address (or autocomplete input, or place ID).
+	Address string
+	// Status is the status string of the response, such as "OK" or "OVER_QUERY_LIMIT";
+	// empty if the response couldn't be decoded.
+	Status string
+	// HTTPStatus is the HTTP status code of the response; 0 if there is no response.
+	HTTPStatus int
+	// Attempt is the 1-based number of this attempt.
+	Attempt int
+	// Duration of this attempt, excluding the rate limit wait.
+	Duration time.Duration
+}
+
+// Options of a geocoding request.
+type Options struct {
+	// Components filter the results, for example {"country": "HU"}
+	// won't return a match from another country.
+	Components map[string]string
+	// Language of the results, for example "hu"; DefaultLanguage if empty.
+	Language string
+	// Region biases the results, as a ccTLD code, for example "hu"; DefaultRegion if empty.
+	Region string
+	// Bounds biases the results toward this viewport.
+	// Results outside are still returned, but when there are more results,
+	// the one inside the Bounds, closest to its center is chosen.
+	Bounds *Bounds
+}
+
+// Bounds is a rectangle given by its south-west and north-east corners.
+type Bounds struct {
+	SouthWest, NorthEast Location
+}
+
+// Contains reports whether loc is inside the rectangle.
+func (b Bounds) Contains(loc Location) bool {
+	return b.SouthWest.Lat <= loc.Lat && loc.Lat <= b.NorthEast.Lat &&
+		b.SouthWest.Lng <= loc.Lng && loc.Lng <= b.NorthEast.Lng
+}
+
+// Center of the rectangle.
+func (b Bounds) Center() Location {
+	return Location{
+		Lat: (b.SouthWest.Lat + b.NorthEast.Lat) / 2,
+		Lng: (b.SouthWest.Lng + b.NorthEast.Lng) / 2,
+	}
+}
+
+func (b Bounds) String() string {
+	f := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	return f(b.SouthWest.Lat) + "," + f(b.SouthWest.Lng) + "|" +
+		f(b.NorthEast.Lat) + "," + f(b.NorthEast.Lng)
+}
+
+func (opts Options) encode(params url.Values) {
+	if opts.Language == "" {
+		opts.Language = DefaultLanguage
+	}
+	if opts.Language != "" {
+		params.Set("language", opts.Language)
+	}
+	if opts.Region == "" {
+		opts.Region = DefaultRegion
+	}
+	if opts.Region != "" {
+		params.Set("region", opts.Region)
+	}
+	if len(opts.Components) != 0 {
+		params.Set("components", encodeComponents(opts.Components))
+	}
+	if opts.Bounds != nil {
+		params.Set("bounds", opts.Bounds.String())
+	}
+}
+
+// encodeComponents returns the "key:value|key:value" form of the components, sorted by key.
+func encodeComponents(components map[string]string) string {
+	keys := make([]string, 0, len(components))
+	for k := range components {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + ":" + components[k]
+	}
+	return strings.Join(keys, "|")
+}
+
+var _ = Geocoder((*Client)(nil))
+
+// NewClient returns a Client using the given API key.
+func NewClient(apiKey string) *Client { return &Client{APIKey: apiKey} }
+
+// NewClientFromEnv returns a Client using the API key
+// from the GOOGLE_MAPS_API_KEY env var.
+func NewClientFromEnv() *Client { return NewClient(os.Getenv("GOOGLE_MAPS_API_KEY")) }
+
 type Location struct {
 	Address string
-	Lat     float64 `json:"lat"`
-	Lng     float64 `json:"lng"`
+	// Precision is the location_type of the result (one of the Precision* constants),
+	// if the geocoder provides it.
+	Precision string  `json:"precision,omitempty"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	// PartialMatch is true if the geocoder did not return an exact match for the address.
+	PartialMatch bool `json:"partial_match,omitempty"`
+	// Raw is the raw response, if Client.KeepRaw is set.
+	Raw json.RawMessage `json:"-"`
 }
 
-var retryStrategy = retry.Strategy{
+// The precisions (location_type) of the Google geocoding results, from the most precise.
+const (
+	PrecisionRooftop           = "ROOFTOP"
+	PrecisionRangeInterpolated = "RANGE_INTERPOLATED"
+	PrecisionGeometricCenter   = "GEOMETRIC_CENTER"
+	PrecisionApproximate       = "APPROXIMATE"
+)
+
+// DefaultRetryStrategy is used by the Clients (and Mapbox) without their own.
+var DefaultRetryStrategy = retry.Strategy{
 	Delay:       100 * time.Millisecond,
 	MaxDelay:    5 * time.Second,
 	MaxDuration: 30 * time.Second,
 	Factor:      2,
 }
 
+// Get the Location of the address, using DefaultClient.
 func Get(ctx context.Context, address string) (Location, error) {
-	var loc Location
+	return DefaultClient.Get(ctx, address)
+}
+
+// Get the Location of the address, using c.Options.
+func (c *Client) Get(ctx context.Context, address string) (Location, error) {
+	return c.GetWithOptions(ctx, address, c.Options)
+}
+
+// GetWithOptions returns the Location of the address, using the given Options.
+func (c *Client) GetWithOptions(ctx context.Context, address string, opts Options) (Location, error) {
 	select {
 	case <-ctx.Done():
-		return loc, ctx.Err()
+		return Location{}, ctx.Err()
 	default:
 	}
-	aURL := gmapsURL
-	aURL = strings.Replace(aURL, "{{.Address}}", url.QueryEscape(address), 1)
-	aURL = strings.Replace(aURL, "{{.APIKey}}", url.QueryEscape(APIKey), 1)
+	loc, err := c.geocode(ctx, address, c.url(NormalizeAddress(address), opts),
+		func(results []mapsResult) (Location, error) { return pickResult(results, opts) })
+	if err == nil && loc.Address == "" {
+		loc.Address = address
+	}
+	return loc, err
+}
 
-	var firstErr error
+// GetNearest returns the Location of the address (using c.Options)
+// closest to ref, from all the results.
+//
+// This resolves the ambiguous addresses (such as "Springfield") toward ref,
+// so it never returns ErrTooManyResults, only ErrNotFound if there is no result.
+func (c *Client) GetNearest(ctx context.Context, address string, ref Location) (Location, error) {
+	select {
+	case <-ctx.Done():
+		return Location{}, ctx.Err()
+	default:
+	}
+	loc, err := c.geocode(ctx, address, c.url(NormalizeAddress(address), c.Options),
+		func(results []mapsResult) (Location, error) { return nearestResult(results, ref) })
+	if err == nil && loc.Address == "" {
+		loc.Address = address
+	}
+	return loc, err
+}
+
+// GetByPlaceID returns the Location of the place (such as Prediction.PlaceID), using c.Options.
+func (c *Client) GetByPlaceID(ctx context.Context, placeID string) (Location, error) {
+	select {
+	case <-ctx.Done():
+		return Location{}, ctx.Err()
+	default:
+	}
+	return c.geocode(ctx, placeID, c.placeIDURL(placeID, c.Options),
+		func(results []mapsResult) (Location, error) { return pickResult(results, c.Options) })
+}
+
+// geocode requests aURL (for address), and returns the result chosen by pick.
+func (c *Client) geocode(ctx context.Context, address, aURL string, pick func([]mapsResult) (Location, error)) (Location, error) {
+	var loc Location
+	if err := c.checkAPIKey(); err != nil {
+		return loc, err
+	}
 	var data mapsResponse
-	for iter := retryStrategy.Start(); ; {
-		if err := gmapsRateLimit.Wait(ctx); err != nil {
-			return loc, err
+	if err := c.getJSON(ctx, address, aURL, &data); err != nil {
+		return loc, err
+	}
+	if err := data.Err(); err != nil {
+		if data.raw != nil {
+			err = &RawError{Err: err, Raw: data.raw}
+		}
+		return loc, err
+	}
+	loc, err := pick(data.Results)
+	if data.raw != nil {
+		if err != nil {
+			err = &RawError{Err: err, Raw: data.raw}
+		} else {
+			loc.Raw = data.raw
+		}
+	}
+	return loc, err
+}
+
+// apiResponse is the common part of the Google Maps API responses.
+type apiResponse interface {
+	status() string
+	Err() error
+}
+
+// rawKeeper is implemented by the responses which can keep their raw JSON.
+type rawKeeper interface {
+	keepRaw(json.RawMessage)
+}
+
+// maxRawSize limits the response size kept with KeepRaw.
+const maxRawSize = 1 << 20
+
+// getJSON decodes the response for aURL (requesting address) into data,
+// using the rate limit and retrying on OVER_QUERY_LIMIT and UNKNOWN_ERROR statuses.
+//
+// HTTP 429 is retried after its Retry-After header, other 4xx errors are not retried.
+func (c *Client) getJSON(ctx context.Context, address, aURL string, data apiResponse) error {
+	limiter := c.RateLimit
+	if limiter == nil {
+		limiter = gmapsRateLimit
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	// aURL contains the API key, so only safeURL goes into the errors
+	safeURL := redactURL(aURL)
+	strategy := retryStrategy(c.Retry)
+	start := time.Now()
+	var firstErr error
+	for iter, attempt := strategy.Start(), 1; ; attempt++ {
+		var retryAfter time.Duration
+		var permanent bool
+		if err := limiter.Wait(ctx); err != nil {
+			if ctx.Err() == nil {
+				if _, ok := ctx.Deadline(); ok {
+					// the limiter won't even wait if the deadline is too close
+					return fmt.Errorf("rate limit: %v: %w", err, context.DeadlineExceeded)
+				}
+			}
+			return err
 		}
 		req, err := http.NewRequest("GET", aURL, nil)
 		if err != nil {
-			return loc, fmt.Errorf("%s: %w", aURL, err)
+			return fmt.Errorf("%s: %w", safeURL, redactError(err))
 		}
-		if err = func() error {
-			resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		info := RequestInfo{Address: address, Attempt: attempt}
+		reqStart := time.Now()
+		err = func() (err error) {
+			resp, err := c.httpClient().Do(req.WithContext(ctx))
 			if err != nil {
-				return fmt.Errorf("%s: %w", aURL, err)
+				return fmt.Errorf("%s: %w", safeURL, redactError(err))
 			}
 			defer resp.Body.Close()
-			if resp.StatusCode > 299 {
-				return fmt.Errorf("%s: %w", aURL, errors.New(resp.Status))
+			info.HTTPStatus = resp.StatusCode
+			if c.KeepRaw {
+				raw, readErr := io.ReadAll(io.LimitReader(resp.Body, maxRawSize))
+				if readErr != nil {
+					return fmt.Errorf("%s: %w", safeURL, readErr)
+				}
+				if rd, ok := data.(rawKeeper); ok && resp.StatusCode < 300 {
+					rd.keepRaw(raw)
+				} else if resp.StatusCode > 299 {
+					defer func() {
+						if err != nil {
+							err = &RawError{Err: err, Raw: raw}
+						}
+					}()
+				}
+				resp.Body = io.NopCloser(bytes.NewReader(raw))
+			}
+			switch code := resp.StatusCode; {
+			case code == http.StatusTooManyRequests:
+				retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+				return fmt.Errorf("%s: %w", resp.Status, ErrOverQuota)
+			case code == http.StatusBadRequest:
+				permanent = true
+				return fmt.Errorf("%s: %w", resp.Status, ErrInvalidRequest)
+			case code == http.StatusUnauthorized || code == http.StatusForbidden:
+				permanent = true
+				return fmt.Errorf("%s: %w", resp.Status, ErrRequestDenied)
+			case code >= 400 && code < 500 && code != http.StatusRequestTimeout:
+				permanent = true
+				return fmt.Errorf("%s: %w", safeURL, errors.New(resp.Status))
+			case code > 299:
+				return fmt.Errorf("%s: %w", safeURL, errors.New(resp.Status))
 			}
 
-			if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
+			if err = json.NewDecoder(resp.Body).Decode(data); err != nil {
 				return fmt.Errorf("decode: %w", err)
 			}
-			if data.Status != "OVER_QUERY_LIMIT" {
-				gmapsRateLimit.SetLimit(gmapsRateLimit.Limit() * 1.1)
-			} else {
-				gmapsRateLimit.SetLimit(gmapsRateLimit.Limit() / 2)
+			info.Status = data.status()
+			if c.Adaptive && c.RateLimit != nil {
+				c.adapt(data.status() == "OVER_QUERY_LIMIT")
+			}
+			switch data.status() {
+			case "OVER_QUERY_LIMIT", "UNKNOWN_ERROR":
+				// retry
+				return data.Err()
 			}
 			return nil
-		}(); err == nil {
-			break
+		}()
+		info.Duration, info.Err = time.Since(reqStart), err
+		if c.OnRequest != nil {
+			c.OnRequest(info)
+		}
+		if c.Metrics != nil {
+			c.Metrics.IncRequest()
+			c.Metrics.ObserveLatency(info.Duration)
+			if err != nil {
+				c.Metrics.IncError(info.errorStatus())
+			}
+		}
+		if err == nil {
+			return nil
 		}
 		if firstErr == nil {
 			firstErr = err
 		}
+		if permanent {
+			return err
+		}
+		if retryAfter > 0 {
+			if time.Since(start)+retryAfter > strategy.MaxDuration {
+				return err
+			}
+			timer := time.NewTimer(retryAfter)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%w: %w", ctx.Err(), firstErr)
+			case <-timer.C:
+			}
+			if c.Metrics != nil {
+				c.Metrics.IncRetry()
+			}
+			continue
+		}
 		if !iter.Next(ctx.Done()) {
-			return loc, firstErr
+			if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(firstErr, ctxErr) {
+				return fmt.Errorf("%w: %w", ctxErr, firstErr)
+			}
+			return firstErr
+		}
+		if c.Metrics != nil {
+			c.Metrics.IncRetry()
 		}
 	}
+}
 
-	switch data.Status {
-	case "OK":
-	case "ZERO_RESULTS":
-		return loc, ErrNotFound
-	default:
-		return loc, errors.New(data.Status)
+// parseRetryAfter parses the Retry-After header value (delay seconds or HTTP date),
+// returning 0 if it is empty or invalid.
+func parseRetryAfter(s string, now time.Time) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0
+		}
+		return time.Duration(n) * time.Second
 	}
-	switch len(data.Results) {
+	if t, err := http.ParseTime(s); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// pickResult returns the only result, or the one in opts.Bounds closest to its center.
+func pickResult(results []mapsResult, opts Options) (Location, error) {
+	switch len(results) {
 	case 0:
-		return loc, ErrNotFound
+		return Location{}, ErrNotFound
 	case 1:
-	default:
+		return results[0].Location(), nil
+	}
+	if opts.Bounds == nil {
+		return Location{}, ErrTooManyResults
+	}
+	center := opts.Bounds.Center()
+	var found bool
+	var loc Location
+	var minDist float64
+	for _, result := range results {
+		l := result.Location()
+		if !opts.Bounds.Contains(l) {
+			continue
+		}
+		if d := center.DistanceTo(l); !found || d < minDist {
+			found, loc, minDist = true, l, d
+		}
+	}
+	if !found {
 		return loc, ErrTooManyResults
 	}
-	result := data.Results[0]
-	loc.Address = result.FormattedAddress
-	loc.Lat, loc.Lng = result.Geometry.Location.Lat, result.Geometry.Location.Lng
 	return loc, nil
 }
 
+// nearestResult returns the result closest to ref.
+func nearestResult(results []mapsResult, ref Location) (Location, error) {
+	if len(results) == 0 {
+		return Location{}, ErrNotFound
+	}
+	var loc Location
+	var minDist float64
+	for i, result := range results {
+		l := result.Location()
+		if d := ref.DistanceTo(l); i == 0 || d < minDist {
+			loc, minDist = l, d
+		}
+	}
+	return loc, nil
+}
+
+func (c *Client) url(address string, opts Options) string {
+	params := url.Values{
+		"key":     {c.APIKey},
+		"sensors": {"false"},
+		"address": {address},
+	}
+	opts.encode(params)
+	return c.baseURL() + geocodePath + "?" + params.Encode()
+}
+
+func (c *Client) placeIDURL(placeID string, opts Options) string {
+	params := url.Values{
+		"key":      {c.APIKey},
+		"place_id": {placeID},
+	}
+	opts.encode(params)
+	return c.baseURL() + geocodePath + "?" + params.Encode()
+}
+
 type mapsResponse struct {
-	Status  string       `json:"status"`
-	Results []mapsResult `json:"results"`
+	Status       string       `json:"status"`
+	ErrorMessage string       `json:"error_message"`
+	Results      []mapsResult `json:"results"`
+	raw          json.RawMessage
+}
+
+func (data *mapsResponse) keepRaw(raw json.RawMessage) { data.raw = raw }
+
+func (data mapsResponse) status() string { return data.Status }
+
+// Err returns the error for the Status.
+func (data mapsResponse) Err() error {
+	var err error
+	switch data.Status {
+	case "OK":
+		return nil
+	case "ZERO_RESULTS", "NOT_FOUND":
+		return ErrNotFound
+	case "OVER_QUERY_LIMIT", "OVER_DAILY_LIMIT":
+		err = ErrOverQuota
+	case "REQUEST_DENIED":
+		err = ErrRequestDenied
+	case "INVALID_REQUEST":
+		err = ErrInvalidRequest
+	case "UNKNOWN_ERROR":
+		err = ErrUnknown
+	default:
+		return errors.New(data.Status)
+	}
+	if data.ErrorMessage != "" {
+		return fmt.Errorf("%s (%s): %w", data.Status, data.ErrorMessage, err)
+	}
+	return fmt.Errorf("%s: %w", data.Status, err)
 }
 
 type mapsResult struct {
 	FormattedAddress string       `json:"formatted_address"`
 	Geometry         mapsGeometry `json:"geometry"`
+	PartialMatch     bool         `json:"partial_match"`
 }
+
+func (result mapsResult) Location() Location {
+	return Location{
+		Address: result.FormattedAddress,
+		Lat:     result.Geometry.Location.Lat,
+		Lng:     result.Geometry.Location.Lng,
+
+		Precision:    result.Geometry.LocationType,
+		PartialMatch: result.PartialMatch,
+	}
+}
+
 type mapsGeometry struct {
-	Location mapsLocation `json:"location"`
+	LocationType string       `json:"location_type"`
+	Location     mapsLocation `json:"location"`
 }
 type mapsLocation struct {
 	Lat float64 `json:"lat"`
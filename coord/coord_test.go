@@ -17,12 +17,25 @@ limitations under the License.
 package coord
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/rogpeppe/retry"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 )
 
 func TestGetCoord(t *testing.T) {
+	cl := NewClientFromEnv()
+	if cl.APIKey == "" {
+		t.Skip("GOOGLE_MAPS_API_KEY is not set")
+	}
 	for i, tc := range []struct {
 		Address string
 		WantErr bool
@@ -31,7 +44,7 @@ func TestGetCoord(t *testing.T) {
 		{"XXXXXXX utca", true},
 	} {
 
-		loc, err := Get(context.Background(), tc.Address)
+		loc, err := cl.Get(context.Background(), tc.Address)
 		t.Logf("%#v [error=%v]", loc, err)
 		if tc.WantErr && err == nil {
 			t.Errorf("%d. want error for %q.", i, tc.Address)
@@ -40,3 +53,356 @@ func TestGetCoord(t *testing.T) {
 		}
 	}
 }
+
+func TestURL(t *testing.T) {
+	cl := NewClient("KEY")
+	got := cl.url("Telepy utca 24", Options{
+		Language: "hu", Region: "hu",
+		Components: map[string]string{"country": "HU", "locality": "Budapest"},
+	})
+	const want = DefaultBaseURL + geocodePath + "?address=Telepy+utca+24&components=country%3AHU%7Clocality%3ABudapest&key=KEY&language=hu&region=hu&sensors=false"
+	if got != want {
+		t.Errorf("got\n\t%s\nwanted\n\t%s", got, want)
+	}
+	defer func(lang, region string) { DefaultLanguage, DefaultRegion = lang, region }(DefaultLanguage, DefaultRegion)
+	DefaultLanguage, DefaultRegion = "hu", "hu"
+	if got = cl.url("Telepy utca 24", Options{Components: map[string]string{"country": "HU", "locality": "Budapest"}}); got != want {
+		t.Errorf("defaults: got\n\t%s\nwanted\n\t%s", got, want)
+	}
+	DefaultLanguage = "de"
+	if got = cl.url("Telepy utca 24", Options{Language: "hu"}); !strings.Contains(got, "language=hu&") {
+		t.Errorf("override: got %s", got)
+	}
+
+	if got, want := cl.placeIDURL("ChIJ", Options{Language: "hu"}), DefaultBaseURL+geocodePath+"?key=KEY&language=hu&place_id=ChIJ&region=hu"; got != want {
+		t.Errorf("place_id: got\n\t%s\nwanted\n\t%s", got, want)
+	}
+}
+
+func TestPickResult(t *testing.T) {
+	mk := func(addr string, lat, lng float64) mapsResult {
+		var r mapsResult
+		r.FormattedAddress = addr
+		r.Geometry.Location.Lat, r.Geometry.Location.Lng = lat, lng
+		return r
+	}
+	results := []mapsResult{
+		mk("Main St, Far", 40, 10),
+		mk("Main St, Edge", 47.1, 19.1),
+		mk("Main St, Center", 47.45, 19.45),
+	}
+	if _, err := pickResult(results, Options{}); err != ErrTooManyResults {
+		t.Errorf("got %v, wanted ErrTooManyResults", err)
+	}
+	bounds := &Bounds{SouthWest: Location{Lat: 47, Lng: 19}, NorthEast: Location{Lat: 48, Lng: 20}}
+	if got := bounds.String(); got != "47,19|48,20" {
+		t.Errorf("got %q", got)
+	}
+	loc, err := pickResult(results, Options{Bounds: bounds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Address != "Main St, Center" {
+		t.Errorf("got %v, wanted Center", loc)
+	}
+
+	if loc, err = nearestResult(results, Location{Lat: 41, Lng: 11}); err != nil {
+		t.Fatal(err)
+	} else if loc.Address != "Main St, Far" {
+		t.Errorf("got %v, wanted Far", loc)
+	}
+	if loc, err = nearestResult(results, Location{Lat: 47.2, Lng: 19}); err != nil {
+		t.Fatal(err)
+	} else if loc.Address != "Main St, Edge" {
+		t.Errorf("got %v, wanted Edge", loc)
+	}
+	if _, err = nearestResult(nil, Location{}); err != ErrNotFound {
+		t.Errorf("got %v, wanted ErrNotFound", err)
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	var data mapsResponse
+	if err := json.Unmarshal([]byte(`{"status":"OK","results":[{
+"formatted_address":"Telepy u., Budapest, 1096 Hungary","partial_match":true,
+"geometry":{"location":{"lat":47.4781,"lng":19.0745},"location_type":"GEOMETRIC_CENTER"}}]}`), &data); err != nil {
+		t.Fatal(err)
+	}
+	loc, err := pickResult(data.Results, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loc.PartialMatch || loc.Precision != PrecisionGeometricCenter {
+		t.Errorf("got %#v", loc)
+	}
+}
+
+func TestStatusErr(t *testing.T) {
+	for status, want := range map[string]error{
+		"OK":               nil,
+		"ZERO_RESULTS":     ErrNotFound,
+		"OVER_QUERY_LIMIT": ErrOverQuota,
+		"OVER_DAILY_LIMIT": ErrOverQuota,
+		"REQUEST_DENIED":   ErrRequestDenied,
+		"INVALID_REQUEST":  ErrInvalidRequest,
+		"UNKNOWN_ERROR":    ErrUnknown,
+	} {
+		err := mapsResponse{Status: status, ErrorMessage: "msg"}.Err()
+		if !errors.Is(err, want) || (want == nil) != (err == nil) {
+			t.Errorf("%s: got %v, wanted %v", status, err, want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	for s, want := range map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"-1":                            0,
+		"junk":                          0,
+		"Mon, 01 May 2023 12:00:10 GMT": 10 * time.Second,
+		"Mon, 01 May 2023 11:00:00 GMT": 0,
+	} {
+		if got := parseRetryAfter(s, now); got != want {
+			t.Errorf("%q: got %v, wanted %v", s, got, want)
+		}
+	}
+}
+
+func TestGetJSONStatus(t *testing.T) {
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch r.URL.Path {
+		case "/429":
+			if n == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/400":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer srv.Close()
+	var infos []RequestInfo
+	c := &Client{
+		RateLimit: rate.NewLimiter(rate.Inf, 1),
+		OnRequest: func(info RequestInfo) { infos = append(infos, info) },
+	}
+	ctx := context.Background()
+
+	var data mapsResponse
+	start := time.Now()
+	if err := c.getJSON(ctx, "429", srv.URL+"/429", &data); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); n != 2 || d < time.Second {
+		t.Errorf("got %d requests in %s, wanted 2 after 1s", n, d)
+	}
+	if len(infos) != 2 || infos[0].HTTPStatus != http.StatusTooManyRequests || infos[0].Err == nil ||
+		infos[1].Attempt != 2 || infos[1].Status != "OK" || infos[1].Address != "429" {
+		t.Errorf("got %+v", infos)
+	}
+
+	n = 0
+	if err := c.getJSON(ctx, "400", srv.URL+"/400", &data); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("got %v, wanted ErrInvalidRequest", err)
+	}
+	if n != 1 {
+		t.Errorf("got %d requests for 400, wanted 1", n)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	c := &Client{RateLimit: rate.NewLimiter(rate.Every(time.Hour), 1), Timeout: 100 * time.Millisecond}
+	c.RateLimit.Allow() // use up the burst
+	start := time.Now()
+	var data mapsResponse
+	if err := c.getJSON(context.Background(), "", "http://localhost:0", &data); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %+v, wanted DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("took %s", d)
+	}
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	var called bool
+	ctx := context.Background()
+	// a BaseURL (such as a mirroring proxy) does not lift the check
+	for _, baseURL := range []string{"", "http://localhost:0"} {
+		c := &Client{BaseURL: baseURL, RateLimit: rate.NewLimiter(rate.Inf, 1), OnRequest: func(RequestInfo) { called = true }}
+		if _, err := c.Get(ctx, "Budapest"); !errors.Is(err, ErrMissingAPIKey) {
+			t.Errorf("%q: Get: got %v, wanted ErrMissingAPIKey", baseURL, err)
+		}
+		if _, err := c.Autocomplete(ctx, "Buda", AutocompleteOptions{}); !errors.Is(err, ErrMissingAPIKey) {
+			t.Errorf("%q: Autocomplete: got %v, wanted ErrMissingAPIKey", baseURL, err)
+		}
+		if _, err := (&Mapbox{BaseURL: baseURL}).Get(ctx, "Budapest"); !errors.Is(err, ErrMissingAPIKey) {
+			t.Errorf("%q: Mapbox: got %v, wanted ErrMissingAPIKey", baseURL, err)
+		}
+	}
+	if called {
+		t.Error("request is made without API key")
+	}
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	c := &Client{RateLimit: rate.NewLimiter(1, 1), Adaptive: true, MinLimit: 0.5, MaxLimit: 2}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); c.adapt(false) }()
+	}
+	wg.Wait()
+	if got := c.RateLimit.Limit(); got != 2 {
+		t.Errorf("raised to %v, wanted 2", got)
+	}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); c.adapt(true) }()
+	}
+	wg.Wait()
+	if got := c.RateLimit.Limit(); got != 0.5 {
+		t.Errorf("lowered to %v, wanted 0.5", got)
+	}
+
+	c.RateLimit.SetLimit(rate.Inf)
+	if c.adapt(false); c.RateLimit.Limit() != rate.Inf {
+		t.Errorf("raised Inf to %v", c.RateLimit.Limit())
+	}
+	if c.adapt(true); c.RateLimit.Limit() != 2 {
+		t.Errorf("lowered Inf to %v, wanted 2", c.RateLimit.Limit())
+	}
+}
+
+func TestKeepRaw(t *testing.T) {
+	const okBody, badBody = `{"status":"ZERO_RESULTS","results":[]}`, `{"error":"bad"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/400" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(badBody))
+			return
+		}
+		w.Write([]byte(okBody))
+	}))
+	defer srv.Close()
+	c := &Client{RateLimit: rate.NewLimiter(rate.Inf, 1), KeepRaw: true}
+	ctx := context.Background()
+
+	var data mapsResponse
+	if err := c.getJSON(ctx, "ok", srv.URL+"/ok", &data); err != nil {
+		t.Fatal(err)
+	}
+	if string(data.raw) != okBody || data.Status != "ZERO_RESULTS" {
+		t.Errorf("got %q (%+v)", data.raw, data)
+	}
+
+	err := c.getJSON(ctx, "400", srv.URL+"/400", &data)
+	var re *RawError
+	if !errors.As(err, &re) || string(re.Raw) != badBody || !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("got %+v", err)
+	}
+}
+
+func TestRetryStrategy(t *testing.T) {
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	c := &Client{
+		RateLimit: rate.NewLimiter(rate.Inf, 1),
+		Retry:     &retry.Strategy{Delay: time.Millisecond, MaxDelay: 10 * time.Millisecond, MaxDuration: 100 * time.Millisecond},
+	}
+	start := time.Now()
+	var data mapsResponse
+	if err := c.getJSON(context.Background(), "500", srv.URL, &data); err == nil {
+		t.Fatal("wanted error")
+	}
+	if d := time.Since(start); d > time.Second || n < 2 {
+		t.Errorf("got %d requests in %s", n, d)
+	}
+}
+
+type testMetrics struct {
+	requests, cacheHits, retries int
+	errors                       map[string]int
+	latencies                    []time.Duration
+}
+
+func (m *testMetrics) IncRequest()                    { m.requests++ }
+func (m *testMetrics) IncCacheHit()                   { m.cacheHits++ }
+func (m *testMetrics) IncRetry()                      { m.retries++ }
+func (m *testMetrics) IncError(status string)         { m.errors[status]++ }
+func (m *testMetrics) ObserveLatency(d time.Duration) { m.latencies = append(m.latencies, d) }
+
+func TestMetrics(t *testing.T) {
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Write([]byte(`{"status":"OVER_QUERY_LIMIT"}`))
+		default:
+			w.Write([]byte(`{"status":"OK","results":[]}`))
+		}
+	}))
+	defer srv.Close()
+	m := testMetrics{errors: make(map[string]int)}
+	c := &Client{
+		RateLimit: rate.NewLimiter(rate.Inf, 1),
+		Retry:     &retry.Strategy{Delay: time.Millisecond, MaxDuration: time.Second},
+		Metrics:   &m,
+	}
+	var data mapsResponse
+	if err := c.getJSON(context.Background(), "x", srv.URL, &data); err != nil {
+		t.Fatal(err)
+	}
+	if m.requests != 3 || m.retries != 2 || len(m.latencies) != 3 ||
+		len(m.errors) != 2 || m.errors["502"] != 1 || m.errors["OVER_QUERY_LIMIT"] != 1 {
+		t.Errorf("got %+v", m)
+	}
+
+	cache := NewLRU(1)
+	cache.Set(NormalizeAddress("Budapest"), Location{Lat: 47.5, Lng: 19})
+	if _, err := Cached(c, cache).Get(context.Background(), "Budapest"); err != nil {
+		t.Fatal(err)
+	}
+	if m.cacheHits != 1 || m.requests != 3 {
+		t.Errorf("got %+v", m)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/maps/api"+geocodePath || r.URL.Query().Get("address") != "budapest" {
+			t.Errorf("got %s", r.URL)
+		}
+		w.Write([]byte(`{"status":"OK","results":[{"formatted_address":"Budapest","geometry":{"location":{"lat":47.5,"lng":19.04}}}]}`))
+	}))
+	defer srv.Close()
+	c := &Client{
+		APIKey:     "KEY",
+		BaseURL:    srv.URL + "/maps/api/",
+		HTTPClient: srv.Client(),
+		RateLimit:  rate.NewLimiter(rate.Inf, 1),
+	}
+	loc, err := c.Get(context.Background(), "Budapest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Address != "Budapest" || loc.Lat != 47.5 || loc.Lng != 19.04 {
+		t.Errorf("got %+v", loc)
+	}
+	if got := (&Client{}).url("x", Options{}); !strings.HasPrefix(got, "https://maps.googleapis.com/maps/api/geocode/json?") {
+		t.Errorf("default URL: got %s", got)
+	}
+}
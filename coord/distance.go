@@ -0,0 +1,38 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import "math"
+
+// EarthRadius is the mean radius of the Earth, in meters.
+const EarthRadius = 6371008.8
+
+// DistanceTo returns the great-circle distance between l and other, in meters.
+func (l Location) DistanceTo(other Location) float64 {
+	return Distance(l.Lat, l.Lng, other.Lat, other.Lng)
+}
+
+// Distance returns the great-circle distance between the two points
+// given in degrees, in meters, using the haversine formula.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	const rad = math.Pi / 180
+	rLat1, rLat2 := lat1*rad, lat2*rad
+	dLat, dLng := (lat2-lat1)*rad, (lng2-lng1)*rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rLat1)*math.Cos(rLat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
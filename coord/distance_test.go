@@ -0,0 +1,43 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	for _, tc := range []struct {
+		Name   string
+		A, B   Location
+		WantKm float64
+	}{
+		{"Budapest-Vienna", Location{Lat: 47.4979, Lng: 19.0402}, Location{Lat: 48.2082, Lng: 16.3738}, 214},
+		{"London-Paris", Location{Lat: 51.5074, Lng: -0.1278}, Location{Lat: 48.8566, Lng: 2.3522}, 344},
+		{"New York-Los Angeles", Location{Lat: 40.7128, Lng: -74.0060}, Location{Lat: 34.0522, Lng: -118.2437}, 3936},
+		{"same", Location{Lat: 47.5, Lng: 19}, Location{Lat: 47.5, Lng: 19}, 0},
+	} {
+		got := tc.A.DistanceTo(tc.B) / 1000
+		if math.Abs(got-tc.WantKm) > 0.01*tc.WantKm+1 {
+			t.Errorf("%s: got %.1fkm, wanted %.0fkm", tc.Name, got, tc.WantKm)
+		}
+		if back := tc.B.DistanceTo(tc.A) / 1000; math.Abs(back-got) > 1e-6 {
+			t.Errorf("%s: not symmetric: %f != %f", tc.Name, back, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileGeocoder is an offline Geocoder of a fixed set of Locations (a gazetteer),
+// such as depots or warehouses.
+//
+// An address is found by its normalized form (see NormalizeAddress),
+// exactly, or as the prefix of exactly one known address.
+type FileGeocoder struct {
+	m    map[string]Location
+	keys []string
+}
+
+var _ = Geocoder((*FileGeocoder)(nil))
+
+// NewFileGeocoder loads the Locations from the file:
+// GeoJSON (a FeatureCollection of Points, with an "address" or "name" property)
+// for .json and .geojson, CSV (address,lat,lng, with an optional header) otherwise.
+func NewFileGeocoder(fn string) (*FileGeocoder, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	var locs []Location
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".json", ".geojson":
+		locs, err = readGeoJSON(fh)
+	default:
+		locs, err = readCSV(fh)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return NewStaticGeocoder(locs...), nil
+}
+
+// NewStaticGeocoder returns a FileGeocoder of the given Locations.
+// Of the same (normalized) addresses, the last one wins.
+func NewStaticGeocoder(locs ...Location) *FileGeocoder {
+	fg := FileGeocoder{m: make(map[string]Location, len(locs))}
+	for _, loc := range locs {
+		fg.m[NormalizeAddress(loc.Address)] = loc
+	}
+	fg.keys = make([]string, 0, len(fg.m))
+	for k := range fg.m {
+		fg.keys = append(fg.keys, k)
+	}
+	sort.Strings(fg.keys)
+	return &fg
+}
+
+// Get the Location of the address: ErrNotFound if no known address matches,
+// ErrTooManyResults if it is the prefix of more.
+func (fg *FileGeocoder) Get(ctx context.Context, address string) (Location, error) {
+	key := NormalizeAddress(address)
+	if loc, ok := fg.m[key]; ok {
+		return loc, nil
+	}
+	if key == "" {
+		return Location{}, ErrNotFound
+	}
+	i := sort.SearchStrings(fg.keys, key)
+	if i >= len(fg.keys) || !strings.HasPrefix(fg.keys[i], key) {
+		return Location{}, ErrNotFound
+	}
+	if i+1 < len(fg.keys) && strings.HasPrefix(fg.keys[i+1], key) {
+		return Location{}, ErrTooManyResults
+	}
+	return fg.m[fg.keys[i]], nil
+}
+
+// readCSV reads the address,lat,lng records, skipping the header, if any.
+func readCSV(r io.Reader) ([]Location, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+	var locs []Location
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return locs, nil
+			}
+			return locs, err
+		}
+		lat, latErr := strconv.ParseFloat(rec[1], 64)
+		lng, lngErr := strconv.ParseFloat(rec[2], 64)
+		if err = errors.Join(latErr, lngErr); err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return locs, fmt.Errorf("line %d: %w", line, err)
+		}
+		locs = append(locs, Location{Address: rec[0], Lat: lat, Lng: lng})
+	}
+}
+
+// readGeoJSON reads the Point features of a FeatureCollection.
+func readGeoJSON(r io.Reader) ([]Location, error) {
+	var fc struct {
+		Features []struct {
+			Geometry struct {
+				Type string `json:"type"`
+				// Coordinates are [longitude, latitude]
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, err
+	}
+	locs := make([]Location, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		if f.Geometry.Type != "Point" || len(f.Geometry.Coordinates) < 2 {
+			continue
+		}
+		var address string
+		for _, k := range []string{"address", "name"} {
+			if address, _ = f.Properties[k].(string); address != "" {
+				break
+			}
+		}
+		if address == "" {
+			return locs, fmt.Errorf("feature %d: no address nor name", i)
+		}
+		locs = append(locs, Location{Address: address,
+			Lng: f.Geometry.Coordinates[0], Lat: f.Geometry.Coordinates[1]})
+	}
+	return locs, nil
+}
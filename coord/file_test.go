@@ -0,0 +1,67 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileGeocoder(t *testing.T) {
+	dir := t.TempDir()
+	csvFn, jsonFn := filepath.Join(dir, "depots.csv"), filepath.Join(dir, "depots.geojson")
+	if err := os.WriteFile(csvFn, []byte("address,lat,lng\n"+
+		"\"Budapest, Telepy utca 24\",47.4781,19.0745\n"+
+		"Debrecen Depot,47.53,21.63\n"+
+		"Debrecen Raktár,47.52,21.62\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonFn, []byte(`{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[19.0745,47.4781]},"properties":{"name":"Budapest, Telepy utca 24"}},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[21.63,47.53]},"properties":{"address":"Debrecen Depot"}},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[21.62,47.52]},"properties":{"address":"Debrecen Raktár"}}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, fn := range []string{csvFn, jsonFn} {
+		fg, err := NewFileGeocoder(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			Address string
+			Err     error
+			Lat     float64
+		}{
+			{Address: " budapest,  TELEPY utca 24", Lat: 47.4781},
+			{Address: "Budapest", Lat: 47.4781},
+			{Address: "debrecen r", Lat: 47.52},
+			{Address: "Debrecen", Err: ErrTooManyResults},
+			{Address: "Szeged", Err: ErrNotFound},
+			{Address: "", Err: ErrNotFound},
+		} {
+			loc, err := fg.Get(ctx, tc.Address)
+			if err != tc.Err {
+				t.Errorf("%s: %q: got %v, wanted %v", filepath.Ext(fn), tc.Address, err, tc.Err)
+			} else if err == nil && loc.Lat != tc.Lat {
+				t.Errorf("%s: %q: got %+v", filepath.Ext(fn), tc.Address, loc)
+			}
+		}
+	}
+}
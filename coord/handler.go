@@ -0,0 +1,84 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Handler returns a http.Handler serving "GET /geocode?address=..." with the Location
+// of the address (from g) as JSON, so g (with its rate limiting and caching)
+// can be used as a sidecar service by non-Go programs.
+//
+// ErrNotFound is mapped to 404, ErrTooManyResults to 409, ErrOverQuota to 429,
+// ErrInvalidRequest to 400, ErrRequestDenied and ErrMissingAPIKey (misconfiguration) to 500,
+// a timeout to 504, other geocoding errors to 502.
+//
+// The response carries only a fixed message for the error, the error itself is logged.
+func Handler(g Geocoder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/geocode") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		address := r.URL.Query().Get("address")
+		if address == "" {
+			http.Error(w, "address is required", http.StatusBadRequest)
+			return
+		}
+		loc, err := g.Get(r.Context(), address)
+		if err != nil {
+			Log("msg", "geocode", "address", address, "error", err)
+			code, msg := errorResponse(err)
+			http.Error(w, msg, code)
+			return
+		}
+		loc.Raw = nil
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err = json.NewEncoder(w).Encode(loc); err != nil {
+			Log("msg", "encode", "address", address, "error", err)
+		}
+	})
+}
+
+// errorResponse returns the HTTP status code and the message for the geocoding error.
+func errorResponse(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, ErrNotFound.Error()
+	case errors.Is(err, ErrTooManyResults):
+		return http.StatusConflict, ErrTooManyResults.Error()
+	case errors.Is(err, ErrOverQuota):
+		return http.StatusTooManyRequests, ErrOverQuota.Error()
+	case errors.Is(err, ErrInvalidRequest):
+		return http.StatusBadRequest, ErrInvalidRequest.Error()
+	case errors.Is(err, ErrRequestDenied), errors.Is(err, ErrMissingAPIKey):
+		return http.StatusInternalServerError, "geocoder is misconfigured"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "geocoding timed out"
+	}
+	return http.StatusBadGateway, "geocoding failed"
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogpeppe/retry"
+	"golang.org/x/time/rate"
+)
+
+func TestHandler(t *testing.T) {
+	h := Handler(Chain(
+		&errGeocoder{err: fmt.Errorf("wrapped: %w", ErrNotFound)},
+		NewStaticGeocoder(
+			Location{Address: "Budapest, Kossuth tér 1", Lat: 47.507, Lng: 19.045},
+			Location{Address: "Budapest, Kossuth tér 2", Lat: 47.508, Lng: 19.046},
+		)))
+	for _, tc := range []struct {
+		h    http.Handler
+		url  string
+		code int
+	}{
+		{h, "/geocode?address=budapest,+kossuth+t%C3%A9r+1", http.StatusOK},
+		{h, "/geocode?address=Budapest,+Kossuth", http.StatusConflict},
+		{h, "/geocode?address=Debrecen", http.StatusNotFound},
+		{h, "/geocode", http.StatusBadRequest},
+		{h, "/other?address=Debrecen", http.StatusNotFound},
+		{Handler(&errGeocoder{err: ErrOverQuota}), "/coord/geocode?address=Debrecen", http.StatusTooManyRequests},
+		{Handler(&errGeocoder{err: ErrRequestDenied}), "/geocode?address=Debrecen", http.StatusInternalServerError},
+		{Handler(&errGeocoder{err: ErrMissingAPIKey}), "/geocode?address=Debrecen", http.StatusInternalServerError},
+		{Handler(&errGeocoder{err: context.DeadlineExceeded}), "/geocode?address=Debrecen", http.StatusGatewayTimeout},
+		{Handler(&errGeocoder{err: errors.New("other")}), "/geocode?address=Debrecen", http.StatusBadGateway},
+	} {
+		resp := httptest.NewRecorder()
+		tc.h.ServeHTTP(resp, httptest.NewRequest("GET", tc.url, nil))
+		if resp.Code != tc.code {
+			t.Errorf("%s: got %d, wanted %d (%s)", tc.url, resp.Code, tc.code, resp.Body.String())
+			continue
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+		var loc Location
+		if err := json.NewDecoder(resp.Body).Decode(&loc); err != nil {
+			t.Fatalf("%s: %+v", tc.url, err)
+		}
+		if loc.Lat != 47.507 || loc.Lng != 19.045 || loc.Address != "Budapest, Kossuth tér 1" {
+			t.Errorf("%s: got %+v", tc.url, loc)
+		}
+	}
+
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest("POST", "/geocode?address=Debrecen", nil))
+	if resp.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: got %d", resp.Code)
+	}
+}
+
+func TestHandlerNoKeyLeak(t *testing.T) {
+	const key = "SECRET-API-KEY"
+	for _, status := range []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError, 0} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch status {
+			case 0:
+				panic(http.ErrAbortHandler)
+			case http.StatusOK:
+				w.Write([]byte(`{"status":"REQUEST_DENIED","error_message":"bad key"}`))
+			default:
+				w.WriteHeader(status)
+			}
+		}))
+		c := &Client{
+			APIKey: key, BaseURL: srv.URL, HTTPClient: srv.Client(),
+			RateLimit: rate.NewLimiter(rate.Inf, 1), Retry: &retry.Strategy{MaxCount: 1},
+		}
+		if _, err := c.Get(context.Background(), "Debrecen"); err == nil {
+			t.Errorf("%d: no error", status)
+		} else if strings.Contains(err.Error(), key) {
+			t.Errorf("%d: key in error: %v", status, err)
+		}
+		resp := httptest.NewRecorder()
+		Handler(c).ServeHTTP(resp, httptest.NewRequest("GET", "/geocode?address=Debrecen", nil))
+		if body := resp.Body.String(); strings.Contains(body, key) || strings.Contains(body, srv.URL) {
+			t.Errorf("%d: got %d %q", status, resp.Code, body)
+		}
+		srv.Close()
+	}
+
+	m := &Mapbox{AccessToken: key, BaseURL: "http://127.0.0.1:0/", Retry: &retry.Strategy{MaxCount: 1}}
+	if _, err := m.Get(context.Background(), "Debrecen"); err == nil || strings.Contains(err.Error(), key) {
+		t.Errorf("Mapbox: got %v", err)
+	}
+}
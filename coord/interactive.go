@@ -43,6 +43,8 @@ type Interactive struct {
 	BaseURL        string
 	MapCenter      Location
 	Location       Location
+	// APIKey for the Google Maps JavaScript API; DefaultClient.APIKey if empty.
+	APIKey string
 
 	inProgressMu sync.Mutex
 	NoDirect     bool
@@ -57,6 +59,10 @@ type staticParams struct {
 }
 
 func (in *Interactive) RenderHTML(w io.Writer, address, callbackURL string) error {
+	apiKey := in.APIKey
+	if apiKey == "" {
+		apiKey = DefaultClient.APIKey
+	}
 	sp := staticParams{
 		Address:        address,
 		DefaultAddress: in.DefaultAddress,
@@ -66,7 +72,7 @@ func (in *Interactive) RenderHTML(w io.Writer, address, callbackURL string) erro
 		LocLat:         fmt.Sprintf("%+f", in.Location.Lat),
 		LocLng:         fmt.Sprintf("%+f", in.Location.Lng),
 		CallbackPath:   callbackURL,
-		APIKey:         APIKey,
+		APIKey:         apiKey,
 	}
 	if err := tmpl.Execute(w, sp); err != nil {
 		return fmt.Errorf("with %#v: %w", sp, err)
@@ -133,7 +139,7 @@ var statikFS embed.FS
 var tmpl *template.Template
 
 func init() {
-	b, err := statikFS.ReadFile("/gmaps.html")
+	b, err := statikFS.ReadFile("assets/gmaps.html")
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rogpeppe/retry"
+	"golang.org/x/time/rate"
+)
+
+const mapboxURL = `https://api.mapbox.com/geocoding/v5/mapbox.places/`
+
+// Mapbox is a Geocoder using the Mapbox geocoding API.
+type Mapbox struct {
+	// Proximity biases the results toward this Location.
+	Proximity *Location
+	// RateLimit limits the requests, if not nil.
+	RateLimit *rate.Limiter
+	// Retry is the retry strategy of the requests; DefaultRetryStrategy if nil.
+	Retry *retry.Strategy
+	// AccessToken for the Mapbox API.
+	AccessToken string
+	// Country limits the results to these (comma separated)
+	// ISO 3166 alpha 2 country codes, for example "hu".
+	Country string
+	// BaseURL of the places endpoint (such as a test server); the Mapbox one if empty.
+	BaseURL string
+	// HTTPClient does the requests; http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+var _ = Geocoder((*Mapbox)(nil))
+
+// Get the Location of the address, from the first returned feature.
+func (m *Mapbox) Get(ctx context.Context, address string) (Location, error) {
+	var loc Location
+	select {
+	case <-ctx.Done():
+		return loc, ctx.Err()
+	default:
+	}
+	if m.AccessToken == "" {
+		return loc, fmt.Errorf("Mapbox: %w (no AccessToken)", ErrMissingAPIKey)
+	}
+	aURL := m.url(NormalizeAddress(address))
+
+	var firstErr error
+	var data mapboxResponse
+	for iter := retryStrategy(m.Retry).Start(); ; {
+		if m.RateLimit != nil {
+			if err := m.RateLimit.Wait(ctx); err != nil {
+				return loc, err
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", aURL, nil)
+		if err != nil {
+			return loc, fmt.Errorf("%s: %w", address, redactError(err))
+		}
+		if err = func() error {
+			client := m.HTTPClient
+			if client == nil {
+				client = http.DefaultClient
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return fmt.Errorf("%s: %w", address, redactError(err))
+			}
+			defer resp.Body.Close()
+			switch resp.StatusCode {
+			case http.StatusUnauthorized, http.StatusForbidden:
+				return fmt.Errorf("%s: %w", resp.Status, ErrRequestDenied)
+			case http.StatusTooManyRequests:
+				return fmt.Errorf("%s: %w", resp.Status, ErrOverQuota)
+			}
+			if resp.StatusCode > 299 {
+				return fmt.Errorf("%s: %w", address, errors.New(resp.Status))
+			}
+			if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
+				return fmt.Errorf("decode: %w", err)
+			}
+			return nil
+		}(); err == nil {
+			break
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if errors.Is(err, ErrRequestDenied) || !iter.Next(ctx.Done()) {
+			return loc, firstErr
+		}
+	}
+	loc, err := data.Location()
+	if err == nil && loc.Address == "" {
+		loc.Address = address
+	}
+	return loc, err
+}
+
+func (m *Mapbox) url(address string) string {
+	params := url.Values{"access_token": {m.AccessToken}}
+	if m.Country != "" {
+		params.Set("country", m.Country)
+	}
+	if m.Proximity != nil {
+		params.Set("proximity",
+			strconv.FormatFloat(m.Proximity.Lng, 'f', -1, 64)+","+
+				strconv.FormatFloat(m.Proximity.Lat, 'f', -1, 64))
+	}
+	base := mapboxURL
+	if m.BaseURL != "" {
+		base = strings.TrimSuffix(m.BaseURL, "/") + "/"
+	}
+	return base + url.PathEscape(address) + ".json?" + params.Encode()
+}
+
+type mapboxResponse struct {
+	Features []mapboxFeature `json:"features"`
+}
+
+type mapboxFeature struct {
+	PlaceName string `json:"place_name"`
+	// Center is [longitude, latitude]
+	Center []float64 `json:"center"`
+}
+
+// Location of the first feature.
+func (data mapboxResponse) Location() (Location, error) {
+	if len(data.Features) == 0 {
+		return Location{}, ErrNotFound
+	}
+	f := data.Features[0]
+	if len(f.Center) < 2 {
+		return Location{}, fmt.Errorf("%s: bad center %v", f.PlaceName, f.Center)
+	}
+	return Location{Address: f.PlaceName, Lng: f.Center[0], Lat: f.Center[1]}, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMapbox(t *testing.T) {
+	m := Mapbox{AccessToken: "TOKEN", Country: "hu", Proximity: &Location{Lat: 47.5, Lng: 19.04}}
+	const wantURL = mapboxURL + "Telepy%20utca%2024.json?access_token=TOKEN&country=hu&proximity=19.04%2C47.5"
+	if got := m.url("Telepy utca 24"); got != wantURL {
+		t.Errorf("got\n\t%s\nwanted\n\t%s", got, wantURL)
+	}
+
+	var data mapboxResponse
+	if err := json.Unmarshal([]byte(`{"type":"FeatureCollection","features":[
+{"place_name":"Telepy utca 24, 1096 Budapest, Hungary","center":[19.0745,47.4781]},
+{"place_name":"Telepy utca, Budapest, Hungary","center":[19.07,47.47]}]}`), &data); err != nil {
+		t.Fatal(err)
+	}
+	loc, err := data.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Lat != 47.4781 || loc.Lng != 19.0745 || loc.Address != "Telepy utca 24, 1096 Budapest, Hungary" {
+		t.Errorf("got %#v", loc)
+	}
+	if _, err = (mapboxResponse{}).Location(); err != ErrNotFound {
+		t.Errorf("got %v, wanted ErrNotFound", err)
+	}
+}
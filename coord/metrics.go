@@ -0,0 +1,59 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"strconv"
+	"time"
+)
+
+// Metrics receives the aggregate metrics of a Client, see Client.Metrics.
+//
+// Back it with the metrics library of your choice (such as Prometheus counters
+// and a histogram); the methods must be safe for concurrent use.
+type Metrics interface {
+	// IncRequest counts an HTTP request (attempt).
+	IncRequest()
+	// IncCacheHit counts a Location served from the cache (see Cached).
+	IncCacheHit()
+	// IncRetry counts a retried request.
+	IncRetry()
+	// IncError counts a failed request (attempt), by its status:
+	// the API status (such as "OVER_QUERY_LIMIT"), the HTTP status code,
+	// or "error" if there is no response.
+	IncError(status string)
+	// ObserveLatency records the duration of a request (attempt).
+	ObserveLatency(time.Duration)
+}
+
+// metricsCarrier is implemented by the Geocoders with Metrics.
+type metricsCarrier interface {
+	metrics() Metrics
+}
+
+func (c *Client) metrics() Metrics { return c.Metrics }
+
+// errorStatus returns the status of the failed request, for Metrics.IncError.
+func (info RequestInfo) errorStatus() string {
+	if info.Status != "" && info.Status != "OK" {
+		return info.Status
+	}
+	if info.HTTPStatus != 0 {
+		return strconv.Itoa(info.HTTPStatus)
+	}
+	return "error"
+}
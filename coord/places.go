@@ -0,0 +1,169 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+const (
+	autocompletePath = "/place/autocomplete/json"
+	placeDetailsPath = "/place/details/json"
+)
+
+// AutocompleteOptions for Autocomplete.
+type AutocompleteOptions struct {
+	// Location biases the predictions toward this point, within Radius meters.
+	Location *Location
+	// Components restricts the results, for example {"country": "hu"}.
+	Components map[string]string
+	// Language of the results.
+	Language string
+	// Types restricts the type of the predictions, for example "address" or "geocode".
+	Types string
+	// Radius in meters, around Location.
+	Radius float64
+
+	// sessionToken of the Session
+	sessionToken string
+}
+
+// Prediction is an autocomplete suggestion.
+type Prediction struct {
+	Description string `json:"description"`
+	PlaceID     string `json:"place_id"`
+}
+
+// Autocomplete returns the as-you-type predictions for input, using the Places API.
+// No predictions (ZERO_RESULTS) is not an error: it returns an empty slice.
+//
+// Use PlaceDetails to get the Location of the chosen Prediction.
+func (c *Client) Autocomplete(ctx context.Context, input string, opts AutocompleteOptions) ([]Prediction, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if err := c.checkAPIKey(); err != nil {
+		return nil, err
+	}
+	var data autocompleteResponse
+	if err := c.getJSON(ctx, input, c.autocompleteURL(input, opts), &data); err != nil {
+		return nil, err
+	}
+	if err := data.Err(); err != nil {
+		return nil, err
+	}
+	if data.Predictions == nil {
+		data.Predictions = []Prediction{}
+	}
+	return data.Predictions, nil
+}
+
+// PlaceDetails returns the Location of the place, for example from Prediction.PlaceID.
+//
+// See Session for cheaper Autocomplete + PlaceDetails usage.
+func (c *Client) PlaceDetails(ctx context.Context, placeID string) (Location, error) {
+	return c.placeDetails(ctx, placeID, "")
+}
+
+func (c *Client) placeDetails(ctx context.Context, placeID, sessionToken string) (Location, error) {
+	var loc Location
+	select {
+	case <-ctx.Done():
+		return loc, ctx.Err()
+	default:
+	}
+	if err := c.checkAPIKey(); err != nil {
+		return loc, err
+	}
+	var data placeDetailsResponse
+	if err := c.getJSON(ctx, placeID, c.placeDetailsURL(placeID, sessionToken), &data); err != nil {
+		return loc, err
+	}
+	if err := data.Err(); err != nil {
+		return loc, err
+	}
+	return data.Result.Location(), nil
+}
+
+func (c *Client) autocompleteURL(input string, opts AutocompleteOptions) string {
+	params := url.Values{
+		"key":   {c.APIKey},
+		"input": {input},
+	}
+	if len(opts.Components) != 0 {
+		params.Set("components", encodeComponents(opts.Components))
+	}
+	if opts.Language != "" {
+		params.Set("language", opts.Language)
+	}
+	if opts.Types != "" {
+		params.Set("types", opts.Types)
+	}
+	if opts.sessionToken != "" {
+		params.Set("sessiontoken", opts.sessionToken)
+	}
+	if opts.Location != nil {
+		params.Set("location",
+			strconv.FormatFloat(opts.Location.Lat, 'f', -1, 64)+","+
+				strconv.FormatFloat(opts.Location.Lng, 'f', -1, 64))
+		if opts.Radius > 0 {
+			params.Set("radius", strconv.FormatFloat(opts.Radius, 'f', -1, 64))
+		}
+	}
+	return c.baseURL() + autocompletePath + "?" + params.Encode()
+}
+
+func (c *Client) placeDetailsURL(placeID, sessionToken string) string {
+	params := url.Values{
+		"key":      {c.APIKey},
+		"place_id": {placeID},
+		"fields":   {"formatted_address,geometry"},
+	}
+	if sessionToken != "" {
+		params.Set("sessiontoken", sessionToken)
+	}
+	return c.baseURL() + placeDetailsPath + "?" + params.Encode()
+}
+
+type autocompleteResponse struct {
+	Status       string       `json:"status"`
+	ErrorMessage string       `json:"error_message"`
+	Predictions  []Prediction `json:"predictions"`
+}
+
+func (data autocompleteResponse) status() string { return data.Status }
+func (data autocompleteResponse) Err() error {
+	if data.Status == "ZERO_RESULTS" {
+		return nil
+	}
+	return mapsResponse{Status: data.Status, ErrorMessage: data.ErrorMessage}.Err()
+}
+
+type placeDetailsResponse struct {
+	Status       string     `json:"status"`
+	ErrorMessage string     `json:"error_message"`
+	Result       mapsResult `json:"result"`
+}
+
+func (data placeDetailsResponse) status() string { return data.Status }
+func (data placeDetailsResponse) Err() error {
+	return mapsResponse{Status: data.Status, ErrorMessage: data.ErrorMessage}.Err()
+}
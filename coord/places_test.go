@@ -0,0 +1,150 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestPlaces(t *testing.T) {
+	c := NewClient("KEY")
+	const wantAC = DefaultBaseURL + autocompletePath + "?components=country%3Ahu&input=Telepy+u&key=KEY&language=hu&location=47.5%2C19.04&radius=5000"
+	if got := c.autocompleteURL("Telepy u", AutocompleteOptions{
+		Components: map[string]string{"country": "hu"},
+		Language:   "hu",
+		Location:   &Location{Lat: 47.5, Lng: 19.04}, Radius: 5000,
+	}); got != wantAC {
+		t.Errorf("got\n\t%s\nwanted\n\t%s", got, wantAC)
+	}
+	const wantPD = DefaultBaseURL + placeDetailsPath + "?fields=formatted_address%2Cgeometry&key=KEY&place_id=ChIJ"
+	if got := c.placeDetailsURL("ChIJ", ""); got != wantPD {
+		t.Errorf("got\n\t%s\nwanted\n\t%s", got, wantPD)
+	}
+
+	var ac autocompleteResponse
+	if err := json.Unmarshal([]byte(`{"status":"OK","predictions":[
+{"description":"Telepy utca, Budapest, Hungary","place_id":"ChIJ1"}]}`), &ac); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ac.Predictions) != 1 || ac.Predictions[0].PlaceID != "ChIJ1" {
+		t.Errorf("got %#v", ac.Predictions)
+	}
+
+	var pd placeDetailsResponse
+	if err := json.Unmarshal([]byte(`{"status":"OK","result":{"formatted_address":"Telepy utca 24, Budapest",
+"geometry":{"location":{"lat":47.4781,"lng":19.0745}}}}`), &pd); err != nil {
+		t.Fatal(err)
+	}
+	if loc := pd.Result.Location(); loc.Lat != 47.4781 || loc.Lng != 19.0745 {
+		t.Errorf("got %#v", loc)
+	}
+	if err := (placeDetailsResponse{Status: "NOT_FOUND"}).Err(); err == nil {
+		t.Error("wanted error for NOT_FOUND")
+	}
+}
+
+func TestAutocompleteZeroResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("input") {
+		case "Xyzzy":
+			w.Write([]byte(`{"status":"ZERO_RESULTS","predictions":[]}`))
+		case "Nothing":
+			w.Write([]byte(`{"status":"ZERO_RESULTS"}`))
+		default:
+			w.Write([]byte(`{"status":"INVALID_REQUEST"}`))
+		}
+	}))
+	defer srv.Close()
+	c := &Client{APIKey: "KEY", BaseURL: srv.URL, HTTPClient: srv.Client(), RateLimit: rate.NewLimiter(rate.Inf, 1)}
+	ctx := context.Background()
+	for _, input := range []string{"Xyzzy", "Nothing"} {
+		preds, err := c.Autocomplete(ctx, input, AutocompleteOptions{})
+		if err != nil {
+			t.Fatalf("%s: %+v", input, err)
+		}
+		if preds == nil || len(preds) != 0 {
+			t.Errorf("%s: got %#v, wanted an empty slice", input, preds)
+		}
+	}
+	if _, err := c.Autocomplete(ctx, "", AutocompleteOptions{}); err == nil {
+		t.Error("wanted error for INVALID_REQUEST")
+	}
+}
+
+func TestSession(t *testing.T) {
+	var tokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.URL.Query().Get("sessiontoken"))
+		switch r.URL.Path {
+		case autocompletePath:
+			w.Write([]byte(`{"status":"OK","predictions":[{"description":"Telepy utca","place_id":"ChIJ1"}]}`))
+		case placeDetailsPath:
+			w.Write([]byte(`{"status":"OK","result":{"formatted_address":"Telepy utca 24",
+"geometry":{"location":{"lat":47.4781,"lng":19.0745}}}}`))
+		default:
+			t.Errorf("got %s", r.URL)
+		}
+	}))
+	defer srv.Close()
+	c := &Client{APIKey: "KEY", BaseURL: srv.URL, HTTPClient: srv.Client(), RateLimit: rate.NewLimiter(rate.Inf, 1)}
+	ctx := context.Background()
+	sess := c.NewSession()
+	for _, input := range []string{"Te", "Telepy", "Telepy u"} {
+		if _, err := sess.Autocomplete(ctx, input, AutocompleteOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sess.PlaceDetails(ctx, "ChIJ1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sess.Autocomplete(ctx, "Kossuth", AutocompleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.PlaceDetails(ctx, "ChIJ1"); err != nil {
+		t.Fatal(err)
+	}
+
+	rUUID := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if len(tokens) != 6 {
+		t.Fatalf("got %d requests, wanted 6", len(tokens))
+	}
+	if !rUUID.MatchString(tokens[0]) {
+		t.Errorf("token %q is not an UUID", tokens[0])
+	}
+	for _, tok := range tokens[1:4] {
+		if tok != tokens[0] {
+			t.Errorf("got %q, wanted the same token in the session: %q", tokens, tokens[0])
+			break
+		}
+	}
+	if tokens[4] == tokens[0] || !rUUID.MatchString(tokens[4]) {
+		t.Errorf("wanted a new token after PlaceDetails, got %q", tokens)
+	}
+	if tokens[5] != "" {
+		t.Errorf("got token %q without Session", tokens[5])
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrInvalidPlusCode is returned (wrapped) by DecodePlusCode for malformed or short codes.
+var ErrInvalidPlusCode = errors.New("invalid plus code")
+
+// Open Location Code (plus code) constants,
+// see https://github.com/google/open-location-code/blob/main/docs/specification.md
+const (
+	olcAlphabet     = "23456789CFGHJMPQRVWX"
+	olcSeparator    = '+'
+	olcPadding      = '0'
+	olcSeparatorPos = 8
+	olcPairLen      = 10
+	olcMaxLen       = 15
+	olcGridCols     = 4
+	olcGridRows     = 5
+	// olcPairPrecision is the precision (1/8000 degree) of the pair digits.
+	olcPairPrecision = 8000
+	// olcLatPrecision and olcLngPrecision are the precisions of the 15 digits codes:
+	// the 5 grid digits divide the pair area to 5^5 rows and 4^5 columns.
+	olcLatPrecision = olcPairPrecision * 3125
+	olcLngPrecision = olcPairPrecision * 1024
+)
+
+// PlusCode returns the 10 digits Open Location Code (plus code) of the Location,
+// such as "8FVC9G8F+6X": an area of about 14x14 meters.
+func (l Location) PlusCode() string { return encodePlusCode(l.Lat, l.Lng, olcPairLen) }
+
+// encodePlusCode returns the Open Location Code of the given length (2, 4, 6, 8 or 10-15 digits).
+func encodePlusCode(lat, lng float64, length int) string {
+	latVal := int64(math.Round(lat*olcLatPrecision)) + 90*olcLatPrecision
+	if latVal < 0 {
+		latVal = 0
+	} else if latVal >= 180*olcLatPrecision {
+		// the north pole is in the last row
+		latVal = 180*olcLatPrecision - 1
+	}
+	const fullLng = 360 * olcLngPrecision
+	lngVal := (int64(math.Round(lng*olcLngPrecision))+180*olcLngPrecision)%fullLng + fullLng
+	lngVal %= fullLng
+
+	var digits [olcMaxLen]byte
+	for i := olcMaxLen - 1; i >= olcPairLen; i-- {
+		digits[i] = olcAlphabet[(latVal%olcGridRows)*olcGridCols+lngVal%olcGridCols]
+		latVal /= olcGridRows
+		lngVal /= olcGridCols
+	}
+	for i := olcPairLen - 2; i >= 0; i -= 2 {
+		digits[i], digits[i+1] = olcAlphabet[latVal%20], olcAlphabet[lngVal%20]
+		latVal /= 20
+		lngVal /= 20
+	}
+	if length < olcSeparatorPos {
+		return string(digits[:length]) + strings.Repeat(string(olcPadding), olcSeparatorPos-length) + string(olcSeparator)
+	}
+	return string(digits[:olcSeparatorPos]) + string(olcSeparator) + string(digits[olcSeparatorPos:length])
+}
+
+// DecodePlusCode returns the center of the area of the full Open Location Code (plus code).
+//
+// Short codes (such as "9G8F+6X", which need a reference location) are not supported.
+func DecodePlusCode(code string) (Location, error) {
+	digits, err := plusCodeDigits(code)
+	if err != nil {
+		return Location{}, fmt.Errorf("%q: %w", code, err)
+	}
+	var lat, lng int64
+	// 400 degrees, so the first digit is 20 degrees
+	latUnit, lngUnit := int64(400*olcLatPrecision), int64(400*olcLngPrecision)
+	for i, c := range []byte(digits) {
+		v := int64(strings.IndexByte(olcAlphabet, c))
+		if i < olcPairLen {
+			if i%2 == 0 {
+				latUnit /= 20
+				lat += v * latUnit
+			} else {
+				lngUnit /= 20
+				lng += v * lngUnit
+			}
+			continue
+		}
+		latUnit /= olcGridRows
+		lngUnit /= olcGridCols
+		lat += v / olcGridCols * latUnit
+		lng += v % olcGridCols * lngUnit
+	}
+	return Location{
+		Lat: float64(2*lat+latUnit)/(2*olcLatPrecision) - 90,
+		Lng: float64(2*lng+lngUnit)/(2*olcLngPrecision) - 180,
+	}, nil
+}
+
+// plusCodeDigits validates the full code, and returns its upper-case digits (without the separator and padding).
+func plusCodeDigits(code string) (string, error) {
+	code = strings.ToUpper(code)
+	sep := strings.IndexByte(code, olcSeparator)
+	if sep < 0 || strings.LastIndexByte(code, olcSeparator) != sep {
+		return "", fmt.Errorf("no or many separators: %w", ErrInvalidPlusCode)
+	}
+	if sep != olcSeparatorPos {
+		return "", fmt.Errorf("short or malformed code: %w", ErrInvalidPlusCode)
+	}
+	head, tail := code[:sep], code[sep+1:]
+	if len(tail) == 1 || len(tail) > olcMaxLen-olcSeparatorPos {
+		return "", fmt.Errorf("bad length after the separator: %w", ErrInvalidPlusCode)
+	}
+	if pad := strings.IndexByte(head, olcPadding); pad >= 0 {
+		if pad == 0 || pad%2 != 0 || strings.Trim(head[pad:], string(olcPadding)) != "" || tail != "" {
+			return "", fmt.Errorf("bad padding: %w", ErrInvalidPlusCode)
+		}
+		head = head[:pad]
+	}
+	digits := head + tail
+	for i := 0; i < len(digits); i++ {
+		if strings.IndexByte(olcAlphabet, digits[i]) < 0 {
+			return "", fmt.Errorf("bad character %q: %w", digits[i], ErrInvalidPlusCode)
+		}
+	}
+	if strings.IndexByte(olcAlphabet, digits[0]) >= 9 || strings.IndexByte(olcAlphabet, digits[1]) >= 18 {
+		return "", fmt.Errorf("out of range: %w", ErrInvalidPlusCode)
+	}
+	return digits, nil
+}
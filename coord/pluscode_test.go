@@ -0,0 +1,97 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPlusCode(t *testing.T) {
+	// from the test/encoding.csv of the reference implementation
+	for _, tc := range []struct {
+		Code     string
+		Lat, Lng float64
+		Length   int
+	}{
+		{"7FG49Q00+", 20.375, 2.775, 6},
+		{"7FG49QCJ+2V", 20.3700625, 2.7821875, 10},
+		{"7FG49QCJ+2VX", 20.3701125, 2.782234375, 11},
+		{"7FG49QCJ+2VXGJ", 20.3701135, 2.78223535156, 13},
+		{"8FVC2222+22", 47.0000625, 8.0000625, 10},
+		{"4VCPPQGP+Q9", -41.2730625, 174.7859375, 10},
+		{"62G20000+", 0.5, -179.5, 4},
+		{"22220000+", -89.5, -179.5, 4},
+		{"7FG40000+", 20.5, 2.5, 4},
+		{"22222222+22", -89.9999375, -179.9999375, 10},
+		{"6VGX0000+", 0.5, 179.5, 4},
+		{"6FH32222+222", 1, 1, 11},
+		{"CFX30000+", 90, 1, 4},
+		{"CFX30000+", 92, 1, 4},
+		{"CFX3X2X2+X2", 90, 1, 10},
+		{"62H20000+", 1, 180, 4},
+		{"62H30000+", 1, 181, 4},
+		{"8FVC9G8F+6X", 47.365590, 8.524997, 10},
+	} {
+		if got := encodePlusCode(tc.Lat, tc.Lng, tc.Length); got != tc.Code {
+			t.Errorf("encode(%v, %v, %d): got %q, wanted %q", tc.Lat, tc.Lng, tc.Length, got, tc.Code)
+		}
+	}
+	if got := (Location{Lat: 47.365590, Lng: 8.524997}).PlusCode(); got != "8FVC9G8F+6X" {
+		t.Errorf("PlusCode: got %q", got)
+	}
+
+	// from the test/decoding.csv of the reference implementation
+	for _, tc := range []struct {
+		Code                       string
+		LatLo, LngLo, LatHi, LngHi float64
+	}{
+		{"7FG49Q00+", 20.35, 2.75, 20.4, 2.8},
+		{"7FG49QCJ+2V", 20.37, 2.782125, 20.370125, 2.78225},
+		{"7FG49QCJ+2VX", 20.3701, 2.78221875, 20.370125, 2.78225},
+		{"7FG49QCJ+2VXGJ", 20.370113, 2.782234375, 20.370114, 2.78223632813},
+		{"8FVC2222+22", 47.0, 8.0, 47.000125, 8.000125},
+		{"4VCPPQGP+Q9", -41.273125, 174.785875, -41.273, 174.786},
+		{"62G20000+", 0.0, -180.0, 1, -179},
+		{"22220000+", -90, -180, -89, -179},
+		{"7fg40000+", 20.0, 2.0, 21.0, 3.0},
+		{"22222222+22", -90.0, -180.0, -89.999875, -179.999875},
+		{"6VGX0000+", 0, 179, 1, 180},
+		{"6FH32222+222", 1, 1, 1.000025, 1.00003125},
+		{"CFX30000+", 89, 1, 90, 2},
+		{"CFX3X2X2+X2", 89.9998750, 1, 90, 1.0001250},
+	} {
+		loc, err := DecodePlusCode(tc.Code)
+		if err != nil {
+			t.Errorf("%q: %+v", tc.Code, err)
+			continue
+		}
+		if lat, lng := (tc.LatLo+tc.LatHi)/2, (tc.LngLo+tc.LngHi)/2; math.Abs(loc.Lat-lat) > 1e-9 || math.Abs(loc.Lng-lng) > 1e-9 {
+			t.Errorf("decode(%q): got %v,%v, wanted %v,%v", tc.Code, loc.Lat, loc.Lng, lat, lng)
+		}
+	}
+
+	for _, code := range []string{
+		"", "9G8F+6X", "8FVC9G8F+6", "8FVC9G8F6X", "8FVC9G8F++6X", "8FVC0000+6X",
+		"8FV00000+", "80000000+", "8FVC9G8F+6XXXXXXXXX", "8FVC9G8F+6I", "WFVC9G8F+6X", "8XVC9G8F+6X",
+	} {
+		if _, err := DecodePlusCode(code); !errors.Is(err, ErrInvalidPlusCode) {
+			t.Errorf("%q: got %v, wanted ErrInvalidPlusCode", code, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// Session groups the Autocomplete calls of one typeahead input,
+// and the PlaceDetails call of the chosen Prediction,
+// with a session token, as the Places API bills such a session cheaper.
+//
+// PlaceDetails closes the session: the next Autocomplete call starts a new one.
+// A Session is safe for concurrent use.
+type Session struct {
+	c     *Client
+	token string
+	mu    sync.Mutex
+}
+
+// NewSession returns a new Session of the Client.
+func (c *Client) NewSession() *Session { return &Session{c: c} }
+
+// Token returns the token of the current session (starting a new one, if needed).
+func (s *Session) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" {
+		s.token = newSessionToken()
+	}
+	return s.token
+}
+
+// Autocomplete is Client.Autocomplete within the session.
+func (s *Session) Autocomplete(ctx context.Context, input string, opts AutocompleteOptions) ([]Prediction, error) {
+	opts.sessionToken = s.Token()
+	return s.c.Autocomplete(ctx, input, opts)
+}
+
+// PlaceDetails is Client.PlaceDetails closing the session.
+func (s *Session) PlaceDetails(ctx context.Context, placeID string) (Location, error) {
+	s.mu.Lock()
+	token := s.token
+	s.token = ""
+	s.mu.Unlock()
+	return s.c.placeDetails(ctx, placeID, token)
+}
+
+// newSessionToken returns a random (version 4) UUID.
+func newSessionToken() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
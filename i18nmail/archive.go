@@ -0,0 +1,121 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/textproto"
+	"path"
+	"strings"
+)
+
+// DefaultMaxArchiveBytes is the default limit of the decompressed size
+// of the archive members of a walk, see WalkOptions.MaxArchiveBytes.
+const DefaultMaxArchiveBytes = 1 << 30
+
+func (opts WalkOptions) maxArchiveBytes() int64 {
+	if opts.MaxArchiveBytes > 0 {
+		return opts.MaxArchiveBytes
+	}
+	return DefaultMaxArchiveBytes
+}
+
+// walkArchive calls todo with the members of the zip or gzip part,
+// and reports whether the part has been handled as an archive.
+//
+// A part which cannot be opened as an archive is not handled.
+func (opts WalkOptions) walkArchive(todo TodoFunc, mp MailPart) (bool, error) {
+	if !opts.DescendArchives || !opts.descend(mp.Level) || mp.Body == nil {
+		return false, nil
+	}
+	type member struct {
+		open func() (io.ReadCloser, error)
+		name string
+	}
+	var members []member
+	switch mp.ContentType {
+	case "application/zip", "application/x-zip-compressed":
+		zr, err := zip.NewReader(mp.Body, mp.Body.Size())
+		if err != nil {
+			logger.Info("open zip", "seq", mp.Seq, "error", err)
+			return false, nil
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			members = append(members, member{name: f.Name, open: f.Open})
+		}
+	case "application/gzip", "application/x-gzip":
+		gr, err := gzip.NewReader(mp.GetBody())
+		if err != nil {
+			logger.Info("open gzip", "seq", mp.Seq, "error", err)
+			return false, nil
+		}
+		name := gr.Name
+		if name == "" {
+			if name = strings.TrimSuffix(mp.FileName(), ".gz"); name == "" {
+				name = "unknown"
+			}
+		}
+		members = append(members, member{name: name, open: func() (io.ReadCloser, error) { return gr, nil }})
+	default:
+		return false, nil
+	}
+
+	if err := opts.enter(mp); err != nil {
+		return true, err
+	}
+	for _, m := range members {
+		child, err := opts.archiveMember(&mp, m.name, m.open)
+		if err != nil {
+			return true, err
+		}
+		if err = opts.account(child.Body.Size()); err != nil {
+			return true, err
+		}
+		if err = opts.visit(todo, child); err != nil {
+			return true, fmt.Errorf("todo(%q): %w", m.name, err)
+		}
+	}
+	return true, opts.leave(mp)
+}
+
+// archiveMember returns the decompressed member as a child of the archive part,
+// returning ErrLimitExceeded if the walk's archive members exceed MaxArchiveBytes.
+func (opts WalkOptions) archiveMember(parent *MailPart, name string, open func() (io.ReadCloser, error)) (MailPart, error) {
+	rc, err := open()
+	if err != nil {
+		return MailPart{}, fmt.Errorf("open %q: %w", name, err)
+	}
+	defer rc.Close()
+	limit := opts.maxArchiveBytes() - opts.state.archiveBytes
+	body, err := MakeSectionReader(io.LimitReader(rc, limit+1), opts.bodyThreshold())
+	if err != nil {
+		return MailPart{}, fmt.Errorf("decompress %q: %w", name, err)
+	}
+	if opts.state.archiveBytes += body.Size(); body.Size() > limit {
+		return MailPart{}, fmt.Errorf("%q: archive members are more than %d bytes: %w", name, opts.maxArchiveBytes(), ErrLimitExceeded)
+	}
+	ct := mime.TypeByExtension(path.Ext(name))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	ct, params, _ := mime.ParseMediaType(ct)
+	hdr := textproto.MIMEHeader{
+		"Content-Type":        {mime.FormatMediaType(ct, params)},
+		"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		"X-FileName":          {safeFn(name, true)},
+	}
+	return MailPart{
+		Body: body, ContentType: ct, MediaType: params, Header: hdr,
+		Parent: parent, Level: parent.Level + 1, Seq: opts.nextSeq(),
+		NoHashHeader: parent.NoHashHeader, hash: parent.Hash(),
+	}, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CollisionStrategy tells SaveAttachments what to do when the file already exists.
+type CollisionStrategy uint8
+
+const (
+	// CollisionRename writes "name-1.ext", "name-2.ext"... instead.
+	CollisionRename = CollisionStrategy(iota)
+	// CollisionOverwrite overwrites the existing file.
+	CollisionOverwrite
+	// CollisionSkip skips the attachment.
+	CollisionSkip
+	// CollisionError stops with an error (wrapping fs.ErrExist).
+	CollisionError
+)
+
+// SaveOptions control SaveAttachments.
+type SaveOptions struct {
+	// MaxSize skips the attachments with larger (decoded) size, if positive.
+	MaxSize int64
+	// Inline saves the inline parts (such as the embedded images of a HTML body), too.
+	Inline bool
+	// OnCollision is the strategy for the already existing files.
+	OnCollision CollisionStrategy
+}
+
+// FileName returns the decoded file name of the part
+// (the filename of the Content-Disposition, or the name of the Content-Type).
+func (mp MailPart) FileName() string {
+	var fn string
+	if _, params, err := mime.ParseMediaType(mp.Header.Get("Content-Disposition")); err == nil {
+		fn = params["filename"]
+	}
+	if fn == "" {
+		fn = mp.MediaType["name"]
+	}
+	if fn == "" {
+		return ""
+	}
+	return HeadDecode(fn)
+}
+
+// isAttachment reports whether the (leaf) part is an attachment, or an inline part.
+//
+// A part is an attachment if its Content-Disposition is "attachment",
+// or it has no Content-Disposition, but has a file name.
+// A part is inline if its Content-Disposition is "inline", and it has a file name or a Content-ID,
+// so the inline text bodies are not inline attachments.
+func (mp MailPart) isAttachment() (attachment, inline bool) {
+	disp, _, _ := mime.ParseMediaType(mp.Header.Get("Content-Disposition"))
+	switch disp {
+	case "attachment":
+		return true, false
+	case "inline":
+		return false, mp.FileName() != "" || mp.ContentID() != ""
+	case "":
+		return mp.FileName() != "", false
+	}
+	return false, false
+}
+
+// SaveAttachments walks the parts of the message, and writes the attachments into dir,
+// with sanitized file names, returning the paths of the written files.
+//
+// Attached messages (message/rfc822) are descended into, so their attachments are saved, too.
+func (mp MailPart) SaveAttachments(dir string, opts SaveOptions) ([]string, error) {
+	var paths []string
+	err := Walk(mp, func(part MailPart) error {
+		if part.Body == nil {
+			return nil
+		}
+		if attachment, inline := part.isAttachment(); !attachment && !(inline && opts.Inline) {
+			return nil
+		}
+		if opts.MaxSize > 0 && part.Body.Size() > opts.MaxSize {
+			logger.Info("skip attachment", "size", part.Body.Size(), "max", opts.MaxSize, "seq", part.Seq)
+			return nil
+		}
+		fn := part.FileName()
+		if fn == "" {
+			ext, _ := mime.ExtensionsByType(part.ContentType)
+			fn = fmt.Sprintf("%d.%d%s", part.Level, part.Seq, append(ext, ".dat")[0])
+		}
+		path, err := saveFile(filepath.Join(dir, safeFn(fn, true)), part.GetBody(), opts.OnCollision)
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+		return nil
+	}, false)
+	return paths, err
+}
+
+// saveFile writes r into fn, returning the path written (empty if skipped).
+func saveFile(fn string, r io.Reader, onCollision CollisionStrategy) (string, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if onCollision == CollisionOverwrite {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	path := fn
+	ext := filepath.Ext(fn)
+	base := strings.TrimSuffix(fn, ext)
+	var fh *os.File
+	for i := 1; ; i++ {
+		var err error
+		if fh, err = os.OpenFile(path, flag, 0640); err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		switch onCollision {
+		case CollisionSkip:
+			logger.Info("skip existing", "file", path)
+			return "", nil
+		case CollisionError:
+			return "", err
+		}
+		path = base + "-" + strconv.Itoa(i) + ext
+	}
+	_, err := io.Copy(fh, r)
+	if closeErr := fh.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("write %q: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// HasMIMEBoundaries reports whether the data (of size bytes in r) looks like
+// a multipart body (RFC 2046 5.1.1), even without a Content-Type:
+// it has a dash-boundary line, and later the matching close-delimiter line.
+//
+// The boundary must start with prefix (such as "----=_Part"), any valid boundary
+// (1-70 bchars, not ending with space) is accepted if the prefix is empty.
+func HasMIMEBoundaries(r io.ReaderAt, size int64, prefix string) bool {
+	br := bufio.NewReader(io.NewSectionReader(r, 0, size))
+	var boundary []byte
+	for {
+		line, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// too long to be a delimiter: skip the rest of the line
+			for err == bufio.ErrBufferFull {
+				_, err = br.ReadSlice('\n')
+			}
+			line = nil
+		}
+		// transport-padding
+		line = bytes.TrimRight(line, " \t\r\n")
+		if bytes.HasPrefix(line, []byte("--")) {
+			if boundary == nil {
+				if b := line[2:]; isBoundary(b) && strings.HasPrefix(string(b), prefix) {
+					boundary = append(make([]byte, 0, len(b)), b...)
+				}
+			} else if len(line) == 2+len(boundary)+2 &&
+				bytes.Equal(line[2:2+len(boundary)], boundary) && bytes.HasSuffix(line, []byte("--")) {
+				return true
+			}
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// isBoundary reports whether b is a valid boundary (RFC 2046 5.1.1).
+func isBoundary(b []byte) bool {
+	if len(b) == 0 || len(b) > 70 || b[len(b)-1] == ' ' {
+		return false
+	}
+	for _, c := range b {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+			strings.IndexByte("'()+_,-./:=? ", c) >= 0) {
+			return false
+		}
+	}
+	return true
+}
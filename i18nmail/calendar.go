@@ -0,0 +1,233 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CalendarEvent is a VEVENT of an iCalendar (RFC 5545) part.
+type CalendarEvent struct {
+	Start, End time.Time
+	Organizer  *Address
+	// Method of the calendar, such as "REQUEST", "CANCEL" or "REPLY".
+	Method string
+	UID    string
+	// Status of the event, such as "CONFIRMED" or "CANCELLED".
+	Status      string
+	Summary     string
+	Description string
+	Location    string
+	Attendees   []*Address
+	// Sequence is the revision of the event: the greater wins for the same UID.
+	Sequence int
+	// AllDay is true if the Start is a date (without time).
+	AllDay bool
+}
+
+// CalendarEvents returns the events of a text/calendar part (nil for other parts).
+//
+// The Body is decoded by its charset parameter, see NewCharsetReader.
+func (mp MailPart) CalendarEvents() ([]CalendarEvent, error) {
+	if mp.ContentType != "text/calendar" || mp.Body == nil {
+		return nil, nil
+	}
+	var r io.Reader = mp.GetBody()
+	if cs := mp.MediaType["charset"]; cs != "" && !strings.EqualFold(cs, "utf-8") {
+		var err error
+		if r, err = NewCharsetReader(cs, r); err != nil {
+			return nil, err
+		}
+	}
+	if m := mp.MediaType["method"]; m != "" {
+		return ParseCalendar(r, strings.ToUpper(m))
+	}
+	return ParseCalendar(r, "")
+}
+
+// ParseCalendar parses the VEVENTs of the iCalendar data.
+//
+// The METHOD of the VCALENDAR overrides the given method.
+func ParseCalendar(r io.Reader, method string) ([]CalendarEvent, error) {
+	var events []CalendarEvent
+	var ev *CalendarEvent
+	var lineNo int
+	err := unfoldLines(r, func(line string) error {
+		lineNo++
+		name, params, value, err := parseContentLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		switch name {
+		case "METHOD":
+			method = strings.ToUpper(value)
+			return nil
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				ev = &CalendarEvent{Method: method}
+			}
+			return nil
+		case "END":
+			if strings.EqualFold(value, "VEVENT") && ev != nil {
+				events = append(events, *ev)
+				ev = nil
+			}
+			return nil
+		}
+		if ev == nil {
+			return nil
+		}
+		switch name {
+		case "UID":
+			ev.UID = value
+		case "SUMMARY":
+			ev.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			ev.Description = unescapeText(value)
+		case "LOCATION":
+			ev.Location = unescapeText(value)
+		case "STATUS":
+			ev.Status = strings.ToUpper(value)
+		case "SEQUENCE":
+			ev.Sequence, _ = strconv.Atoi(value)
+		case "DTSTART":
+			if ev.Start, ev.AllDay, err = parseCalendarTime(value, params); err != nil {
+				return fmt.Errorf("line %d: DTSTART: %w", lineNo, err)
+			}
+		case "DTEND":
+			if ev.End, _, err = parseCalendarTime(value, params); err != nil {
+				return fmt.Errorf("line %d: DTEND: %w", lineNo, err)
+			}
+		case "ORGANIZER":
+			ev.Organizer = calendarAddress(value, params)
+		case "ATTENDEE":
+			ev.Attendees = append(ev.Attendees, calendarAddress(value, params))
+		}
+		return nil
+	})
+	if err != nil {
+		return events, err
+	}
+	for i := range events {
+		// the METHOD may be after the VEVENTs
+		if events[i].Method == "" {
+			events[i].Method = method
+		}
+	}
+	return events, nil
+}
+
+// unfoldLines calls f with each unfolded (RFC 5545 3.1) content line.
+func unfoldLines(r io.Reader, f func(string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	var buf strings.Builder
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			buf.WriteString(line[1:])
+			continue
+		}
+		if buf.Len() != 0 {
+			if err := f(buf.String()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+		buf.WriteString(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if buf.Len() != 0 {
+		return f(buf.String())
+	}
+	return nil
+}
+
+// parseContentLine splits the "NAME;PARAM=value:value" line.
+func parseContentLine(line string) (name string, params map[string]string, value string, err error) {
+	var inQuote bool
+	i := strings.IndexFunc(line, func(r rune) bool {
+		if r == '"' {
+			inQuote = !inQuote
+		}
+		return r == ':' && !inQuote
+	})
+	if i < 0 {
+		return "", nil, "", fmt.Errorf("no colon in %q", line)
+	}
+	head, value := line[:i], line[i+1:]
+	name, rest, _ := strings.Cut(head, ";")
+	name = strings.ToUpper(name)
+	for rest != "" {
+		var kv string
+		if j := indexUnquoted(rest, ';'); j >= 0 {
+			kv, rest = rest[:j], rest[j+1:]
+		} else {
+			kv, rest = rest, ""
+		}
+		k, v, _ := strings.Cut(kv, "=")
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return name, params, value, nil
+}
+
+func indexUnquoted(s string, c byte) int {
+	var inQuote bool
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuote = !inQuote
+		case c:
+			if !inQuote {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+var textUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+// unescapeText unescapes a TEXT value.
+func unescapeText(s string) string { return textUnescaper.Replace(s) }
+
+// parseCalendarTime parses the DATE or DATE-TIME value, in its TZID (time.Local if unknown),
+// and reports whether it is a DATE.
+func parseCalendarTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// calendarAddress returns the Address of the "mailto:" value, with the CN parameter as Name.
+func calendarAddress(value string, params map[string]string) *Address {
+	if len(value) > len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		value = value[len("mailto:"):]
+	}
+	return &Address{Name: params["CN"], Address: value}
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+var (
+	charsetsMu sync.RWMutex
+	charsets   = make(map[string]DecoderFunc)
+)
+
+// RegisterCharset registers the decoder (to utf-8) of the charset (case insensitive),
+// which is consulted before the built-in ones, by the header decoding and NewCharsetReader
+// (so by the body decoding of WalkOptions.DecodeCharset, and CalendarEvents).
+//
+// For example, with a golang.org/x/text/encoding/charmap:
+//
+//	i18nmail.RegisterCharset("x-cp852", func(r io.Reader) io.Reader {
+//		return charmap.CodePage852.NewDecoder().Reader(r)
+//	})
+func RegisterCharset(name string, decoder DecoderFunc) {
+	charsetsMu.Lock()
+	charsets[normalizeCharset(name)] = decoder
+	charsetsMu.Unlock()
+}
+
+func normalizeCharset(name string) string { return strings.ToLower(strings.TrimSpace(name)) }
+
+// registeredCharset returns the registered decoder of the charset, or nil.
+func registeredCharset(name string) DecoderFunc {
+	charsetsMu.RLock()
+	decoder := charsets[normalizeCharset(name)]
+	charsetsMu.RUnlock()
+	return decoder
+}
+
+// NewCharsetReader returns a reader which decodes input from charset to utf-8,
+// using the registered decoders (see RegisterCharset) first.
+//
+// For an unknown charset, it returns input as is, with an error.
+func NewCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	if decoder := registeredCharset(charset); decoder != nil {
+		return decoder(input), nil
+	}
+	//enc, err := ianaindex.MIME.Get(charset)
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return input, fmt.Errorf("%q: %w", charset, err)
+	}
+	return transform.NewReader(input, enc.NewDecoder()), nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Envelope is the decoded and parsed summary of a message's header.
+type Envelope struct {
+	// Date of the message, zero if missing.
+	Date time.Time
+	// Subject is the decoded (utf-8) Subject.
+	Subject string
+	// From, To and Cc are the parsed addresses, with decoded names.
+	From, To, Cc []*Address
+}
+
+// Envelope returns the decoded Subject, the parsed From, To, Cc addresses
+// and the Date of the part - this is meaningful for the root part
+// (or a message/rfc822 one).
+//
+// Missing headers are not errors; the returned error joins all the
+// parse errors, and the Envelope holds everything that could be parsed.
+func (mp MailPart) Envelope() (Envelope, error) {
+	hdr := Header(mp.Header)
+	env := Envelope{Subject: hdr.Decode("Subject")}
+	var errs []error
+	var err error
+	if env.Date, err = hdr.Date(); err != nil && !errors.Is(err, ErrHeaderNotPresent) {
+		errs = append(errs, fmt.Errorf("Date: %w", err))
+	}
+	for _, f := range []struct {
+		dest *[]*Address
+		key  string
+	}{{&env.From, "From"}, {&env.To, "To"}, {&env.Cc, "Cc"}} {
+		if *f.dest, err = hdr.AddressList(f.key); err != nil && !errors.Is(err, ErrHeaderNotPresent) {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+		}
+	}
+	return env, errors.Join(errs...)
+}
@@ -29,10 +29,6 @@ import (
 	"time"
 
 	"github.com/tgulacsi/go/text"
-
-	//"golang.org/x/text/encoding/ianaindex"
-	"golang.org/x/text/encoding/htmlindex"
-	"golang.org/x/text/transform"
 )
 
 // Layouts suitable for passing to time.Parse.
@@ -219,16 +215,7 @@ func (a *Address) String() string {
 }
 
 // WordDecoder decodes mime rords.
-var WordDecoder = &mime.WordDecoder{
-	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
-		//enc, err := ianaindex.MIME.Get(charset)
-		enc, err := htmlindex.Get(charset)
-		if err != nil {
-			return input, err
-		}
-		return transform.NewReader(input, enc.NewDecoder()), nil
-	},
-}
+var WordDecoder = &mime.WordDecoder{CharsetReader: NewCharsetReader}
 
 // AddressParser is a mail address parser.
 var AddressParser = &mail.AddressParser{WordDecoder: WordDecoder}
@@ -257,8 +244,9 @@ func DecodeRFC2047Word(s string) (string, error) {
 		return "", errors.New("mail: address not RFC 2047 encoded")
 	}
 	charset, encMark := strings.ToLower(fields[1]), strings.ToLower(fields[2])
+	decoder := registeredCharset(charset)
 	enc := text.GetEncoding(charset)
-	if enc == nil {
+	if decoder == nil && enc == nil {
 		return "", fmt.Errorf("mail: charset not supported: %q", charset)
 	}
 
@@ -273,7 +261,12 @@ func DecodeRFC2047Word(s string) (string, error) {
 		return "", fmt.Errorf("mail: RFC 2047 encoding not supported: %q", encMark)
 	}
 
-	dec, err := io.ReadAll(text.NewReader(r, enc))
+	if decoder != nil {
+		r = decoder(r)
+	} else {
+		r = text.NewReader(r, enc)
+	}
+	dec, err := io.ReadAll(r)
 	if err != nil {
 		return "", err
 	}
@@ -5,7 +5,11 @@
 package i18nmail
 
 import (
+	"io"
+	"strings"
 	"testing"
+
+	"golang.org/x/text/encoding/charmap"
 )
 
 var headDecodeTests = [][2]string{
@@ -27,3 +31,26 @@ func TestHeadDecode(t *testing.T) {
 		t.Logf("%d. head: %s", i, result)
 	}
 }
+
+func TestRegisterCharset(t *testing.T) {
+	const head = "=?x-test-cp852?q?=B5rv=A1zt=FBr=8B?="
+	if got := HeadDecode(head); got != head {
+		t.Errorf("unknown charset: got %q", got)
+	}
+	RegisterCharset("X-Test-CP852", func(r io.Reader) io.Reader {
+		return charmap.CodePage852.NewDecoder().Reader(r)
+	})
+	if got := HeadDecode(head); got != "Árvíztűrő" {
+		t.Errorf("HeadDecode: got %q", got)
+	}
+	if got, err := DecodeRFC2047Word(head); err != nil || got != "Árvíztűrő" {
+		t.Errorf("DecodeRFC2047Word: got %q, %+v", got, err)
+	}
+	r, err := NewCharsetReader("x-test-cp852", strings.NewReader("\xb5rv\xa1zt\xfbr\x8b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(r); err != nil || string(b) != "Árvíztűrő" {
+		t.Errorf("NewCharsetReader: got %q, %+v", b, err)
+	}
+}
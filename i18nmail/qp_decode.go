@@ -0,0 +1,65 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+)
+
+// NewStrictQPDecoder returns a quoted-printable decoder which returns an error
+// for the invalid escapes (an "=" not followed by two hex digits or a line break),
+// instead of passing them through.
+func NewStrictQPDecoder(r io.Reader) io.Reader {
+	return quotedprintable.NewReader(&qpChecker{br: bufio.NewReader(r)})
+}
+
+// qpChecker passes through its input, checking the "=" escapes line by line.
+type qpChecker struct {
+	br     *bufio.Reader
+	line   []byte
+	offset int64
+	err    error
+}
+
+func (qc *qpChecker) Read(p []byte) (int, error) {
+	for len(qc.line) == 0 {
+		if qc.err != nil {
+			return 0, qc.err
+		}
+		qc.line, qc.err = qc.br.ReadBytes('\n')
+		if err := checkQPLine(qc.line); err != nil {
+			qc.line, qc.err = nil, fmt.Errorf("line at byte %d: %w", qc.offset, err)
+		}
+		qc.offset += int64(len(qc.line))
+	}
+	n := copy(p, qc.line)
+	qc.line = qc.line[n:]
+	return n, nil
+}
+
+func checkQPLine(line []byte) error {
+	for i := bytes.IndexByte(line, '='); i >= 0; i = bytes.IndexByte(line, '=') {
+		rest := line[i+1:]
+		if len(bytes.TrimRight(rest, " \t\r\n")) == 0 { // soft line break
+			return nil
+		}
+		if len(rest) < 2 || !isHexDigit(rest[0]) || !isHexDigit(rest[1]) {
+			if len(rest) > 2 {
+				rest = rest[:2]
+			}
+			return fmt.Errorf("invalid escape %q", "="+string(rest))
+		}
+		line = rest[2:]
+	}
+	return nil
+}
+
+func isHexDigit(b byte) bool {
+	return '0' <= b && b <= '9' || 'A' <= b && b <= 'F' || 'a' <= b && b <= 'f'
+}
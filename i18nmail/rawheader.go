@@ -0,0 +1,108 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/textproto"
+	"strings"
+)
+
+// maxRawHeaderSize limits the size of the kept raw header of a part.
+const maxRawHeaderSize = 1 << 20
+
+// RawHeaders returns the header fields of the part as they appeared on the wire:
+// in their original order, the keys and the values unmodified (not decoded, not canonicalized),
+// the values with their leading whitespace and folding (CRLF and whitespace) kept,
+// only the line ending of the last line stripped.
+//
+// It is available only with WalkOptions.KeepRawHeaders, and for the parts read from the wire
+// (the messages and the multipart children, not the archive members); nil otherwise.
+func (mp MailPart) RawHeaders() [][2]string {
+	if len(mp.rawHeader) == 0 {
+		return nil
+	}
+	var fields [][2]string
+	for rest := mp.rawHeader; len(rest) != 0; {
+		var line []byte
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, rest = rest[:i+1], rest[i+1:]
+		} else {
+			line, rest = rest, nil
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) != 0 {
+			// continuation
+			fields[len(fields)-1][1] += string(line)
+			continue
+		}
+		if k, v, ok := bytes.Cut(line, []byte(":")); ok {
+			fields = append(fields, [2]string{string(k), string(v)})
+		}
+	}
+	for i, f := range fields {
+		fields[i][1] = strings.TrimSuffix(strings.TrimSuffix(f[1], "\n"), "\r")
+	}
+	return fields
+}
+
+// setRawHeader records the order of the header keys, and keeps the raw header, if keep is true.
+func (mp *MailPart) setRawHeader(raw []byte, keep bool) {
+	if keep {
+		mp.rawHeader = raw
+	}
+	seen := make(map[string]struct{})
+	for _, f := range (MailPart{rawHeader: raw}).RawHeaders() {
+		k := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(f[0]))
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			mp.headerOrder = append(mp.headerOrder, k)
+		}
+	}
+}
+
+// readRawHeader returns the header bytes (till the first empty line, without it).
+func readRawHeader(br *bufio.Reader) []byte {
+	var hdr []byte
+	for len(hdr) < maxRawHeaderSize {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimRight(line, "\r\n")) == 0 {
+			break
+		}
+		hdr = append(hdr, line...)
+		if err != nil {
+			break
+		}
+	}
+	return hdr
+}
+
+// multipartRawHeaders returns the raw headers of the parts of the multipart body.
+func multipartRawHeaders(r io.Reader, boundary string) [][]byte {
+	br := bufio.NewReader(r)
+	delim := []byte("--" + boundary)
+	var hdrs [][]byte
+	for {
+		line, err := br.ReadSlice('\n')
+		if bytes.HasPrefix(line, delim) {
+			rest := bytes.TrimRight(line[len(delim):], " \t\r\n")
+			if bytes.Equal(rest, []byte("--")) {
+				break
+			}
+			if len(rest) == 0 && err == nil {
+				hdrs = append(hdrs, readRawHeader(br))
+				continue
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			break
+		}
+	}
+	return hdrs
+}
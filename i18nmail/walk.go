@@ -19,6 +19,7 @@ import (
 	"net/textproto"
 	"net/url"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -29,20 +30,44 @@ import (
 )
 
 // MaxWalkDepth is the maximum depth Walk will descend.
-const (
-	MaxWalkDepth  = 32
-	bodyThreshold = 1 << 20
-)
+const MaxWalkDepth = 32
 
 var (
 	logger = logr.Discard()
 
-	// CheckEncoding is true if we should check Base64 encodings
-	CheckEncoding = true
+	// CheckEncoding makes the base64 and quoted-printable decoding strict:
+	// a malformed body (of a non-multipart part) results in an ErrBadEncoding error.
+	// Otherwise (by default) the bodies are decoded leniently, skipping the invalid bytes
+	// (such as the trailing spaces, or junk after the padding, common in real mail).
+	CheckEncoding = false
 
 	// SaveBadInput is true if we should save bad input
 	SaveBadInput = false
 
+	// BodyThreshold is the size limit of the bodies kept in memory:
+	// the bigger ones are spilled into a temp file (see MakeSectionReader).
+	// WalkOptions.BodyThreshold overrides it for a walk.
+	BodyThreshold = 1 << 20
+
+	// SpillDir is the directory for the temp files of the parts bigger than the in-memory threshold.
+	// The default temp directory is used if empty.
+	SpillDir string
+
+	// SkipPanics makes Walk skip the parts whose decoding panics;
+	// by default such a panic stops the walk with an ErrPanic error.
+	SkipPanics = false
+
+	// ErrPanic is returned (wrapped) when decoding a part panics.
+	ErrPanic = errors.New("panic while decoding part")
+
+	// ErrBadEncoding is returned (wrapped) for a malformed base64 or quoted-printable body,
+	// if CheckEncoding is true.
+	ErrBadEncoding = errors.New("bad transfer encoding")
+
+	// ErrLimitExceeded is returned (wrapped) when the walk exceeds
+	// WalkOptions.MaxParts or WalkOptions.MaxTotalBytes.
+	ErrLimitExceeded = errors.New("limit exceeded")
+
 	// ErrStopWalk shall be returned by the TodoFunc to stop the walk silently.
 	ErrStopWalk = errors.New("stop the walk")
 )
@@ -82,6 +107,29 @@ type MailPart struct {
 	Level int
 	// Seq is a sequence number
 	Seq int
+	// NoHashHeader prevents adding the hash of the full message to the Header (as HashKeyName),
+	// it is available with Hash() only. Inherited by the descendants.
+	NoHashHeader bool
+	// Duplicate is true if the body of the part is the same as of an earlier part
+	// of the walk (with WalkOptions.Dedup set to DedupMark).
+	Duplicate bool
+	// TransferEncoding is the original Content-Transfer-Encoding of the part,
+	// the Body is already decoded.
+	TransferEncoding string
+
+	hash string
+	// rawHeader is the header as read, see WalkOptions.KeepRawHeaders
+	rawHeader []byte
+	// headerOrder is the order of the header keys as read, for WriteTo
+	headerOrder []string
+}
+
+// Hash returns the hash of the full message, this part is in.
+func (mp MailPart) Hash() string {
+	if mp.hash != "" {
+		return mp.hash
+	}
+	return mp.Header.Get(HashKeyName)
 }
 
 // String returns some string representation of the part.
@@ -95,7 +143,20 @@ func (mp MailPart) String() string {
 
 // Spawn returns a descendant of the MailPart (Level+1, Parent=*mp, next sequence).
 func (mp *MailPart) Spawn() MailPart {
-	return MailPart{Parent: mp, Level: mp.Level + 1, Seq: nextSeqInt()}
+	return MailPart{Parent: mp, Level: mp.Level + 1, Seq: nextSeqInt(),
+		NoHashHeader: mp.NoHashHeader, hash: mp.Hash()}
+}
+
+// ContentID returns the Content-ID of the part, without the angle brackets.
+func (mp MailPart) ContentID() string {
+	cid := strings.TrimSpace(mp.Header.Get("Content-ID"))
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(cid, "<"), ">"))
+}
+
+// ContentLocation returns the Content-Location of the part,
+// with the folding whitespace removed.
+func (mp MailPart) ContentLocation() string {
+	return strings.Join(strings.Fields(HeadDecode(mp.Header.Get("Content-Location"))), "")
 }
 
 // DecoderFunc is a type of a decoder (io.Reader wrapper)
@@ -105,8 +166,28 @@ type DecoderFunc func(io.Reader) io.Reader
 //
 // If the read length is below the threshold, then the bytes are read into memory;
 // otherwise, a temp file is created, and mmap-ed.
+//
+// The temp file is created in SpillDir.
 func MakeSectionReader(r io.Reader, threshold int) (*io.SectionReader, error) {
-	return iohlp.MakeSectionReader(r, threshold)
+	sr, res, err := iohlp.MakeSectionReaderResult(r, threshold, SpillDir)
+	logger.V(1).Info("MakeSectionReader", "size", res.Size, "spilled", res.Spilled)
+	return sr, err
+}
+
+// NewMailPart returns a root MailPart (to Walk) of the message read from r,
+// buffered by MakeSectionReader.
+func NewMailPart(r io.Reader) (MailPart, error) {
+	sr, err := MakeSectionReader(r, BodyThreshold)
+	if err != nil {
+		return MailPart{}, err
+	}
+	return MailPart{Body: sr, Seq: nextSeqInt()}, nil
+}
+
+// NewMailPartFromReaderAt returns a root MailPart (to Walk) of the message in r
+// (such as an *os.File), without copying it.
+func NewMailPartFromReaderAt(r io.ReaderAt, size int64) MailPart {
+	return MailPart{Body: io.NewSectionReader(r, 0, size), Seq: nextSeqInt()}
 }
 
 // GetBody returns a fresh copy of mp.Body.
@@ -118,6 +199,218 @@ func (mp MailPart) GetBody() *io.SectionReader {
 //
 // By default this is recursive, except dontDescend is true.
 func Walk(part MailPart, todo TodoFunc, dontDescend bool) error {
+	return walk(part, todo, WalkOptions{DontDescend: dontDescend})
+}
+
+// WalkWith is Walk with options.
+func WalkWith(part MailPart, todo TodoFunc, opts WalkOptions) error {
+	return walk(part, todo, opts)
+}
+
+// WalkOptions control the recursion of WalkWith and WalkMessage.
+type WalkOptions struct {
+	// Level overrides the starting depth: the Level of the message's parent
+	// (parent.Level, or 0 without parent, by default).
+	Level int
+	// MaxDepth overrides MaxWalkDepth, if positive.
+	MaxDepth int
+	// DontDescend stops the recursion at the first level.
+	DontDescend bool
+	// MaxParts limits the number of parts (including the multipart containers)
+	// of the whole walk, if positive.
+	MaxParts int
+	// MaxTotalBytes limits the sum of the decoded sizes of the parts
+	// (including the multipart containers) of the whole walk, if positive.
+	MaxTotalBytes int64
+
+	// Dedup controls the handling of the parts whose body is the same as an earlier one's.
+	Dedup DedupMode
+
+	// BodyThreshold overrides the package-level BodyThreshold, if positive.
+	BodyThreshold int
+
+	// DeterministicSeq numbers the parts (MailPart.Seq) from 1 within the walk,
+	// instead of the process-wide sequence, so the numbers are the same in every run.
+	DeterministicSeq bool
+
+	// KeepRawHeaders keeps the raw header bytes of the parts, for MailPart.RawHeaders.
+	KeepRawHeaders bool
+
+	// DecodeCharset converts the body of the text/* parts to UTF-8 by their charset parameter,
+	// with NewCharsetReader (so the decoders of RegisterCharset are used),
+	// and sets their charset to utf-8.
+	// The parts with an unknown charset are left as is.
+	DecodeCharset bool
+
+	// DescendArchives makes the walk to descend into the zip and gzip parts
+	// (application/zip, application/gzip), calling todo with their members
+	// (as children of the archive part), instead of the archive.
+	// It is limited by MaxDepth, and MaxArchiveBytes.
+	DescendArchives bool
+	// MaxArchiveBytes limits the decompressed size of all the archive members of the walk,
+	// DefaultMaxArchiveBytes if not positive.
+	MaxArchiveBytes int64
+
+	// state is shared by the whole walk
+	state *walkState
+	// rawHeader of the message, for WalkMessage
+	rawHeader []byte
+	// visitor of WalkVisitor
+	visitor *Visitor
+}
+
+// Visitor receives the parts of WalkVisitor.
+type Visitor struct {
+	// OnEnter is called with a container (multipart or message) part, before its children.
+	OnEnter func(MailPart) error
+	// OnLeave is called with a container part, after its children.
+	OnLeave func(MailPart) error
+	// OnPart is called with the leaf parts, as the TodoFunc of Walk.
+	OnPart TodoFunc
+}
+
+// errVisitor marks the errors of the Visitor, which must stop the walk.
+var errVisitor = errors.New("visitor")
+
+// WalkVisitor walks over the parts of the email as WalkWith,
+// but calls v.OnEnter and v.OnLeave for the multipart and message containers, too,
+// so the tree structure (Level, Parent) can be followed.
+func WalkVisitor(part MailPart, v Visitor, opts WalkOptions) error {
+	opts.visitor = &v
+	todo := v.OnPart
+	if todo == nil {
+		todo = func(MailPart) error { return nil }
+	}
+	return walk(part, todo, opts)
+}
+
+func (opts WalkOptions) enter(mp MailPart) error {
+	if opts.visitor == nil || opts.visitor.OnEnter == nil {
+		return nil
+	}
+	if err := opts.visitor.OnEnter(mp); err != nil {
+		return fmt.Errorf("%w: %w", errVisitor, err)
+	}
+	return nil
+}
+
+func (opts WalkOptions) leave(mp MailPart) error {
+	if opts.visitor == nil || opts.visitor.OnLeave == nil {
+		return nil
+	}
+	if err := opts.visitor.OnLeave(mp); err != nil {
+		return fmt.Errorf("%w: %w", errVisitor, err)
+	}
+	return nil
+}
+
+// DedupMode is the duplicate part handling mode of the walk.
+type DedupMode uint8
+
+const (
+	// DedupNone calls todo for every part.
+	DedupNone = DedupMode(iota)
+	// DedupMark calls todo for every part, with MailPart.Duplicate set for the repeated ones.
+	DedupMark
+	// DedupSkip calls todo only once for the parts with the same body.
+	DedupSkip
+)
+
+type walkState struct {
+	seen         map[string]struct{}
+	parts        int
+	bytes        int64
+	archiveBytes int64
+	seq          int
+}
+
+// withState returns the opts with the shared state initialized, if needed.
+func (opts WalkOptions) withState() WalkOptions {
+	if opts.state == nil && (opts.MaxParts > 0 || opts.MaxTotalBytes > 0 || opts.Dedup != DedupNone ||
+		opts.DeterministicSeq || opts.DescendArchives) {
+		opts.state = &walkState{}
+	}
+	return opts
+}
+
+// nextSeq returns the next sequence number of the walk (see DeterministicSeq),
+// or the process-wide one.
+func (opts WalkOptions) nextSeq() int {
+	if !opts.DeterministicSeq || opts.state == nil {
+		return nextSeqInt()
+	}
+	opts.state.seq++
+	return opts.state.seq
+}
+
+// visit calls todo with the part, marking or skipping it if its body has been seen already,
+// or with the members of the part, if it is an archive (see DescendArchives).
+func (opts WalkOptions) visit(todo TodoFunc, mp MailPart) error {
+	if ok, err := opts.walkArchive(todo, mp); ok {
+		return err
+	}
+	if opts.Dedup == DedupNone || opts.state == nil {
+		return todo(mp)
+	}
+	h := sha512.New512_224()
+	if _, err := io.Copy(h, mp.GetBody()); err != nil {
+		return fmt.Errorf("hash part: %w", err)
+	}
+	key := string(h.Sum(nil))
+	if _, ok := opts.state.seen[key]; ok {
+		if opts.Dedup == DedupSkip {
+			logger.V(1).Info("skip duplicate", "seq", mp.Seq, "ct", mp.ContentType)
+			return nil
+		}
+		mp.Duplicate = true
+	} else {
+		if opts.state.seen == nil {
+			opts.state.seen = make(map[string]struct{})
+		}
+		opts.state.seen[key] = struct{}{}
+	}
+	return todo(mp)
+}
+
+// account a part with the given decoded size, returning ErrLimitExceeded if a limit is exceeded.
+func (opts WalkOptions) account(size int64) error {
+	if opts.state == nil {
+		return nil
+	}
+	opts.state.parts++
+	opts.state.bytes += size
+	if opts.MaxParts > 0 && opts.state.parts > opts.MaxParts {
+		return fmt.Errorf("more than %d parts: %w", opts.MaxParts, ErrLimitExceeded)
+	}
+	if opts.MaxTotalBytes > 0 && opts.state.bytes > opts.MaxTotalBytes {
+		return fmt.Errorf("more than %d bytes: %w", opts.MaxTotalBytes, ErrLimitExceeded)
+	}
+	return nil
+}
+
+func (opts WalkOptions) maxDepth() int {
+	if opts.MaxDepth > 0 {
+		return opts.MaxDepth
+	}
+	return MaxWalkDepth
+}
+
+func (opts WalkOptions) bodyThreshold() int {
+	if opts.BodyThreshold > 0 {
+		return opts.BodyThreshold
+	}
+	return BodyThreshold
+}
+
+// descend reports whether the walk should descend into a part at the given level.
+func (opts WalkOptions) descend(level int) bool {
+	return !opts.DontDescend && level < opts.maxDepth()
+}
+
+func walk(part MailPart, todo TodoFunc, opts WalkOptions) error {
+	if err := opts.enter(part); err != nil {
+		return err
+	}
 	h := sha512.New512_224()
 	if _, err := io.Copy(h, part.GetBody()); err != nil {
 		return fmt.Errorf("ready part: %w", err)
@@ -128,6 +421,8 @@ func Walk(part MailPart, todo TodoFunc, dontDescend bool) error {
 	if err != nil {
 		body = part.GetBody()
 	}
+	// the header order is kept even without KeepRawHeaders, for WriteTo
+	opts.rawHeader = readRawHeader(bufio.NewReader(io.NewSectionReader(body, 0, body.Size())))
 	msg, err := mail.ReadMessage(io.MultiReader(
 		body,
 		bytes.NewReader([]byte("\r\n\r\n")),
@@ -139,43 +434,75 @@ func Walk(part MailPart, todo TodoFunc, dontDescend bool) error {
 		logger.Error(err, "ReadAndHashMessage", "message", string(b[:n]))
 		return fmt.Errorf("mail.ReadMessage: %w", err)
 	}
-	if hsh != "" {
+	if part.NoHashHeader {
+		part.hash = hsh
+	} else if hsh != "" {
 		msg.Header["X-Hash"] = []string{hsh}
 	}
 	// force a new SectionReader
-	return WalkMessage(msg, todo, dontDescend, &part)
+	if err := WalkMessage(msg, todo, opts, &part); err != nil {
+		return err
+	}
+	return opts.leave(part)
 }
 
 // WalkMessage walks over the parts of the email, calling todo on every part.
 // The part.Body given to todo is reused, so read if you want to use it!
 //
-// By default this is recursive, except dontDescend is true.
-func WalkMessage(msg *mail.Message, todo TodoFunc, dontDescend bool, parent *MailPart) error {
-	hdr := textproto.MIMEHeader(DecodeHeaders(msg.Header))
-	ct, params, decoder, err := getCT(hdr)
-	if err != nil {
+// By default this is recursive, except opts.DontDescend is true,
+// till opts.MaxDepth (MaxWalkDepth by default).
+func WalkMessage(msg *mail.Message, todo TodoFunc, opts WalkOptions, parent *MailPart) error {
+	opts = opts.withState()
+	seq := opts.nextSeq()
+	var ct string
+	var params map[string]string
+	var childBody *io.SectionReader
+	var te string
+	if err := recoverPanic(seq, func() error {
+		hdr := textproto.MIMEHeader(DecodeHeaders(msg.Header))
+		te = strings.ToLower(hdr.Get("Content-Transfer-Encoding"))
+		var decoder func(io.Reader) io.Reader
+		var err error
+		if ct, params, decoder, err = getCT(hdr); err != nil {
+			return err
+		}
+		decoder = opts.charsetDecoder(ct, params, hdr, decoder)
+		msg.Header = mail.Header(hdr)
+		r := msg.Body
+		if decoder != nil {
+			r = decoder(msg.Body)
+		}
+		if childBody, err = MakeSectionReader(r, opts.bodyThreshold()); err != nil {
+			logger.Error(err, "read body")
+			return fmt.Errorf("MakeSectionReader: %w", err)
+		}
+		return nil
+	}); err != nil {
+		if SkipPanics && errors.Is(err, ErrPanic) {
+			logger.Error(err, "skip message")
+			return nil
+		}
 		return err
 	}
-	msg.Header = mail.Header(hdr)
-	r := msg.Body
-	if decoder != nil {
-		r = decoder(msg.Body)
-	}
-	childBody, err := MakeSectionReader(r, bodyThreshold)
-	if err != nil {
-		logger.Error(err, "read body")
-		return fmt.Errorf("MakeSectionReader: %w", err)
+	if err := opts.account(childBody.Size()); err != nil {
+		return err
 	}
 	logger.V(1).Info("Walk message", "headers", msg.Header, "body", childBody.Size())
-	if err != nil {
-		return fmt.Errorf("WalkMessage: %w", err)
-	}
+	isMessage := ct == "message/rfc822" || ct == "message/global"
 	if ct == "" {
 		ct = "message/rfc822"
 	}
 	var level int
+	var noHashHeader bool
+	var hsh string
 	if parent != nil {
 		level = parent.Level
+		noHashHeader, hsh = parent.NoHashHeader, parent.hash
+	}
+	if opts.Level != 0 {
+		level = opts.Level
+		// the descendants have their parent's Level
+		opts.Level = 0
 	}
 	child := MailPart{
 		Body:        childBody,
@@ -183,17 +510,30 @@ func WalkMessage(msg *mail.Message, todo TodoFunc, dontDescend bool, parent *Mai
 		Header: textproto.MIMEHeader(msg.Header),
 		Parent: parent,
 		Level:  level + 1,
-		Seq:    nextSeqInt(),
+		Seq:    seq,
+
+		NoHashHeader:     noHashHeader,
+		TransferEncoding: te,
+		hash:             hsh,
 	}
+	child.setRawHeader(opts.rawHeader, opts.KeepRawHeaders)
+	opts.rawHeader = nil
 	//fmt.Println("WM", child.Seq, "ct", child.ContentType)
-	if hsh := msg.Header.Get("X-Hash"); hsh != "" && child.Header.Get(HashKeyName) == "" {
+	if hsh := msg.Header.Get("X-Hash"); hsh != "" && !noHashHeader && child.Header.Get(HashKeyName) == "" {
 		child.Header.Add(HashKeyName, hsh)
 	}
 	//debugf("message sequence=%d content-type=%q params=%v", child.Seq, ct, params)
+	if isMessage && opts.descend(child.Level) {
+		// the body is an email itself
+		if err := walk(child, todo, opts); err != nil {
+			return fmt.Errorf("WalkMessage/Walk(seq=%d, ct=%q): %w", child.Seq, ct, err)
+		}
+		return nil
+	}
 	if !strings.HasPrefix(ct, "multipart/") {
-		return todo(child)
+		return opts.visit(todo, child)
 	}
-	if err = WalkMultipart(child, todo, dontDescend); err != nil {
+	if err := walkMultipart(child, todo, opts); err != nil {
 		return fmt.Errorf("WalkMessage/WalkMultipart(seq=%d, ct=%q): %w", child.Seq, ct, err)
 	}
 	return nil
@@ -204,6 +544,11 @@ func WalkMessage(msg *mail.Message, todo TodoFunc, dontDescend bool, parent *Mai
 //
 // By default this is recursive, except dontDescend is true.
 func WalkMultipart(mp MailPart, todo TodoFunc, dontDescend bool) error {
+	return walkMultipart(mp, todo, WalkOptions{DontDescend: dontDescend})
+}
+
+func walkMultipart(mp MailPart, todo TodoFunc, opts WalkOptions) error {
+	opts = opts.withState()
 	logger := logger.WithValues("level", mp.Level, "seq", mp.Seq)
 	boundary := mp.MediaType["boundary"]
 	if len(mp.MediaType) == 0 || boundary == "" {
@@ -224,6 +569,10 @@ func WalkMultipart(mp MailPart, todo TodoFunc, dontDescend bool) error {
 			strings.NewReader("\r\n"),
 		),
 		boundary)
+	if err := opts.enter(mp); err != nil {
+		return err
+	}
+	rawHeaders := multipartRawHeaders(mp.GetBody(), boundary)
 	nextPart := parts.NextPart
 	if mp.Header.Get("Content-Transfer-Encoding") == "" {
 		nextPart = parts.NextRawPart
@@ -237,44 +586,73 @@ func WalkMultipart(mp MailPart, todo TodoFunc, dontDescend bool) error {
 			}
 			break
 		}
-		sr, readErr := MakeSectionReader(part, bodyThreshold)
-		if readErr != nil {
-			logger.Error(readErr, "read part")
-			return fmt.Errorf("read part: %w", readErr)
-		}
 		i++
-		part.Header = DecodeHeaders(part.Header)
+		seq := opts.nextSeq()
+		logger := logger.WithValues("seq", seq, "level", mp.Level+1)
 		var ct string
-		ct, params, decoder, ctErr := getCT(part.Header)
-		if ctErr != nil {
-			return fmt.Errorf("%d.getCT(%v): %w", i, part.Header, ctErr)
-		}
-		child := MailPart{
-			Body:        sr,
-			ContentType: ct, MediaType: params,
-			Header: part.Header,
-			Parent: &mp,
-			Level:  mp.Level + 1,
-			Seq:    nextSeqInt(),
-		}
-		logger := logger.WithValues("seq", child.Seq, "level", child.Level)
-		//fmt.Println(i, child.Seq, child.Header.Get("Content-Type"))
-		child.Header.Add(HashKeyName, mp.Header.Get(HashKeyName))
-		logger.Info("child", "ct", child.ContentType, "params", child.MediaType, "header", child.Header)
+		var child MailPart
+		if err = recoverPanic(seq, func() error {
+			sr, readErr := MakeSectionReader(part, opts.bodyThreshold())
+			if readErr != nil {
+				logger.Error(readErr, "read part")
+				return fmt.Errorf("read part: %w", readErr)
+			}
+			part.Header = DecodeHeaders(part.Header)
+			te := strings.ToLower(part.Header.Get("Content-Transfer-Encoding"))
+			ct, params, decoder, ctErr := getCT(part.Header)
+			if ctErr != nil {
+				return fmt.Errorf("%d.getCT(%v): %w", i, part.Header, ctErr)
+			}
+			decoder = opts.charsetDecoder(ct, params, part.Header, decoder)
+			child = MailPart{
+				Body:        sr,
+				ContentType: ct, MediaType: params,
+				Header: part.Header,
+				Parent: &mp,
+				Level:  mp.Level + 1,
+				Seq:    seq,
 
-		if decoder != nil {
-			childBody, err := MakeSectionReader(decoder(child.Body), bodyThreshold)
-			if err != nil {
-				return fmt.Errorf("MakeSectionReader(threshold=%d): %w", bodyThreshold, err)
+				NoHashHeader:     mp.NoHashHeader,
+				TransferEncoding: te,
+				hash:             mp.hash,
 			}
-			child.Body = childBody
+			if i <= len(rawHeaders) {
+				child.setRawHeader(rawHeaders[i-1], opts.KeepRawHeaders)
+			}
+			//fmt.Println(i, child.Seq, child.Header.Get("Content-Type"))
+			if !mp.NoHashHeader {
+				child.Header.Add(HashKeyName, mp.Header.Get(HashKeyName))
+			}
+			logger.Info("child", "ct", child.ContentType, "params", child.MediaType, "header", child.Header)
+
+			if decoder != nil {
+				childBody, err := MakeSectionReader(decoder(child.Body), opts.bodyThreshold())
+				if err != nil {
+					return fmt.Errorf("MakeSectionReader(threshold=%d): %w", opts.bodyThreshold(), err)
+				}
+				child.Body = childBody
+			}
+			return nil
+		}); err != nil {
+			if SkipPanics && errors.Is(err, ErrPanic) {
+				logger.Error(err, "skip part")
+				continue
+			}
+			return err
+		}
+		if err = opts.account(child.Body.Size()); err != nil {
+			return err
 		}
-		if isMultipart := strings.HasPrefix(ct, "multipart/"); !dontDescend &&
+		ct = child.ContentType
+		if isMultipart := strings.HasPrefix(ct, "multipart/"); opts.descend(child.Level) &&
 			(isMultipart && child.MediaType["boundary"] != "" || strings.HasPrefix(ct, "message/")) {
 			if isMultipart {
-				err = WalkMultipart(child, todo, dontDescend)
+				err = walkMultipart(child, todo, opts)
 			} else {
-				err = Walk(child, todo, dontDescend)
+				err = walk(child, todo, opts)
+			}
+			if errors.Is(err, ErrLimitExceeded) || errors.Is(err, errVisitor) {
+				return err
 			}
 			if err != nil {
 				logger.Info("Walk child", "error", err)
@@ -296,12 +674,22 @@ func WalkMultipart(mp MailPart, todo TodoFunc, dontDescend bool) error {
 			}
 			child.Header.Add("X-FileName", safeFn(fn, true))
 			//logger.Info("todo", "child", child)
-			if err = todo(child); err != nil {
+			if err = opts.visit(todo, child); err != nil {
 				return fmt.Errorf("todo(%q): %w", fn, err)
 			}
 		}
 	}
-	return nil
+	return opts.leave(mp)
+}
+
+// recoverPanic calls f, and converts its panic to an ErrPanic error, tagged with the part's seq.
+func recoverPanic(seq int, f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("part seq=%d: %w: %v", seq, ErrPanic, r)
+		}
+	}()
+	return f()
 }
 
 // returns the content-type, params and a decoder for the body of the multipart
@@ -357,6 +745,8 @@ func getCT(
 	contentType = nct
 	const cteKey = "Content-Transfer-Encoding"
 	te := strings.ToLower(hdr.Get(cteKey))
+	// a multipart must not be encoded, so such an encoding is not to be taken seriously
+	strict := CheckEncoding && !strings.HasPrefix(contentType, "multipart/")
 	switch te {
 	case "", "7bit", "8bit", "binary":
 		// https://stackoverflow.com/questions/25710600/content-transfer-encoding-7bit-or-8-bit
@@ -366,12 +756,20 @@ func getCT(
 			hdr.Del(cteKey)
 			//return &b64ForceDecoder{Encoding: base64.StdEncoding, r: r}
 			//return B64FilterReader(r, base64.StdEncoding)
-			logger.Info("base64 decoder")
+			logger.Info("base64 decoder", "strict", strict)
+			if strict {
+				// ignores CR and LF only
+				return strictReader{Reader: base64.NewDecoder(base64.StdEncoding, r), te: te}
+			}
 			return NewB64Decoder(base64.StdEncoding, r)
 		}
 	case "quoted-printable":
 		decoder = func(r io.Reader) io.Reader {
 			hdr.Del(cteKey)
+			if strict {
+				logger.Info("quotedprintable decoder", "strict", true)
+				return strictReader{Reader: NewStrictQPDecoder(r), te: te}
+			}
 			br := bufio.NewReaderSize(r, 1024)
 			first, _ := br.Peek(1024)
 			enc := qprintable.BinaryEncoding
@@ -387,6 +785,47 @@ func getCT(
 	return
 }
 
+// charsetDecoder returns decoder, followed by the conversion of the text/* body to UTF-8,
+// if opts.DecodeCharset is set and the charset is known.
+// The charset in params and hdr is changed to utf-8 then.
+func (opts WalkOptions) charsetDecoder(
+	contentType string, params map[string]string, hdr textproto.MIMEHeader,
+	decoder func(io.Reader) io.Reader,
+) func(io.Reader) io.Reader {
+	cs := params["charset"]
+	if !opts.DecodeCharset || !strings.HasPrefix(contentType, "text/") ||
+		cs == "" || strings.EqualFold(cs, "utf-8") || strings.EqualFold(cs, "us-ascii") {
+		return decoder
+	}
+	if _, err := NewCharsetReader(cs, strings.NewReader("")); err != nil {
+		logger.Info("unknown charset", "charset", cs, "error", err)
+		return decoder
+	}
+	params["charset"] = "utf-8"
+	hdr.Set("Content-Type", mime.FormatMediaType(contentType, params))
+	return func(r io.Reader) io.Reader {
+		if decoder != nil {
+			r = decoder(r)
+		}
+		r, _ = NewCharsetReader(cs, r)
+		return r
+	}
+}
+
+// strictReader marks the errors of the decoding Reader with ErrBadEncoding.
+type strictReader struct {
+	io.Reader
+	te string
+}
+
+func (sr strictReader) Read(p []byte) (int, error) {
+	n, err := sr.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%s: %w: %w", sr.te, ErrBadEncoding, err)
+	}
+	return n, err
+}
+
 // HashBytes returns a hash (sha512_224 atm) for the given bytes
 func HashBytes(data []byte) string {
 	h := sha512.New512_224()
@@ -414,3 +853,45 @@ func DecodeHeaders(hdr map[string][]string) map[string][]string {
 	}
 	return hdr
 }
+
+// FlattenOptions control FlattenHeaders.
+type FlattenOptions struct {
+	// LowerKeys makes the keys lowercase, instead of canonical (see textproto.CanonicalMIMEHeaderKey).
+	LowerKeys bool
+	// FirstOnly keeps only the first value of a repeated header,
+	// instead of joining the values with ", ".
+	FirstOnly bool
+}
+
+// FlattenHeaders returns the headers with single, decoded string values.
+//
+// The keys which are the same after the case conversion are merged.
+func FlattenHeaders(hdr map[string][]string, opts FlattenOptions) map[string]string {
+	keys := make([]string, 0, len(hdr))
+	for k := range hdr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make(map[string][]string, len(hdr))
+	for _, k := range keys {
+		key := textproto.CanonicalMIMEHeaderKey(k)
+		if opts.LowerKeys {
+			key = strings.ToLower(key)
+		}
+		for _, v := range hdr[k] {
+			values[key] = append(values[key], HeadDecode(v))
+		}
+	}
+	m := make(map[string]string, len(values))
+	for k, vv := range values {
+		if len(vv) == 0 {
+			continue
+		}
+		if opts.FirstOnly {
+			m[k] = vv[0]
+		} else {
+			m[k] = strings.Join(vv, ", ")
+		}
+	}
+	return m
+}
@@ -5,19 +5,27 @@
 package i18nmail
 
 import (
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"encoding/base64"
+	"errors"
 	"io"
+	"io/fs"
 	"mime"
 	"mime/multipart"
 	"net/mail"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr/testr"
+	"golang.org/x/text/encoding/charmap"
 )
 
 func TestMailAddress(t *testing.T) {
@@ -134,3 +142,856 @@ func TestWalk(t *testing.T) {
 		})
 	}
 }
+
+func TestNoHashHeader(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nsecond\r\n" +
+		"--BB--\r\n"
+	for _, noHashHeader := range []bool{false, true} {
+		var hashes []string
+		mp := MailPart{
+			Body:         io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg))),
+			NoHashHeader: noHashHeader,
+		}
+		if err := Walk(mp, func(mp MailPart) error {
+			if got := mp.Header.Get(HashKeyName) != ""; got == noHashHeader {
+				t.Errorf("noHashHeader=%t: header %s=%q", noHashHeader, HashKeyName, mp.Header.Get(HashKeyName))
+			}
+			hashes = append(hashes, mp.Hash())
+			return nil
+		}, false); err != nil {
+			t.Fatal(err)
+		}
+		if len(hashes) != 2 || hashes[0] == "" || hashes[0] != hashes[1] {
+			t.Errorf("noHashHeader=%t: got %q", noHashHeader, hashes)
+		}
+	}
+}
+
+func TestWalkPanic(t *testing.T) {
+	defer func(cr func(string, io.Reader) (io.Reader, error)) { WordDecoder.CharsetReader = cr }(WordDecoder.CharsetReader)
+	WordDecoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		panic("bad charset " + charset)
+	}
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\nSubject: =?x-panic?q?abc?=\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nsecond\r\n" +
+		"--BB--\r\n"
+	for _, skip := range []bool{false, true} {
+		SkipPanics = skip
+		var n int
+		err := Walk(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+			func(mp MailPart) error { n++; return nil },
+			false)
+		if skip {
+			if err != nil || n != 1 {
+				t.Errorf("skip: got %d parts, error %+v", n, err)
+			}
+		} else if !errors.Is(err, ErrPanic) {
+			t.Errorf("got %+v, wanted ErrPanic", err)
+		}
+	}
+	SkipPanics = false
+}
+
+func TestWalkRFC822(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: message/rfc822\r\n\r\n" +
+		"From: b@example.com\r\nSubject: inner\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: text/html\r\n\r\n<p>second</p>\r\n" +
+		"--BB--\r\n"
+	var cts []string
+	if err := Walk(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+		func(mp MailPart) error {
+			cts = append(cts, mp.ContentType)
+			var levels []int
+			for p := &mp; p != nil; p = p.Parent {
+				levels = append(levels, p.Level)
+			}
+			for i := 1; i < len(levels); i++ {
+				if levels[i-1] <= levels[i] {
+					t.Errorf("%s: levels are not decreasing: %v", mp.ContentType, levels)
+				}
+			}
+			return nil
+		},
+		false,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(cts) != 2 || cts[0] != "text/plain" || cts[1] != "text/html" {
+		t.Errorf("got %q", cts)
+	}
+}
+
+func TestWalkMessageOptions(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: message/rfc822\r\n\r\n" +
+		"From: b@example.com\r\nContent-Type: text/plain\r\n\r\ninner\r\n" +
+		"--BB--\r\n"
+	for _, tc := range []struct {
+		opts   WalkOptions
+		want   []string
+		levels []int
+	}{
+		{WalkOptions{}, []string{"text/plain", "text/plain"}, []int{2, 3}},
+		{WalkOptions{MaxDepth: 1}, []string{"text/plain", "message/rfc822"}, []int{2, 2}},
+		{WalkOptions{Level: 5}, []string{"text/plain", "text/plain"}, []int{7, 8}},
+	} {
+		m, err := mail.ReadMessage(strings.NewReader(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		var levels []int
+		if err := WalkMessage(m, func(mp MailPart) error {
+			got = append(got, mp.ContentType)
+			levels = append(levels, mp.Level)
+			return nil
+		}, tc.opts, nil); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, " ") != strings.Join(tc.want, " ") || len(levels) != len(tc.levels) ||
+			levels[0] != tc.levels[0] || levels[1] != tc.levels[1] {
+			t.Errorf("%+v: got %q %v, wanted %q %v", tc.opts, got, levels, tc.want, tc.levels)
+		}
+	}
+}
+
+func TestContentID(t *testing.T) {
+	mp := MailPart{Header: textproto.MIMEHeader{
+		"Content-Id":       {" <logo@example.com> "},
+		"Content-Location": {"http://example.com/\r\n logo.png"},
+	}}
+	if got := mp.ContentID(); got != "logo@example.com" {
+		t.Errorf("ContentID: got %q", got)
+	}
+	if got := mp.ContentLocation(); got != "http://example.com/logo.png" {
+		t.Errorf("ContentLocation: got %q", got)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	const head = "From: =?UTF-8?Q?Gul=C3=A1csi_Tam=C3=A1s?= <a@example.com>\r\nSubject: =?UTF-8?Q?=C3=A1rv=C3=ADzt=C5=B1r=C5=91?=\r\n" +
+		"MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n"
+	for _, tc := range []struct {
+		te, body string
+	}{
+		{"", "plain body\r\n"},
+		{"base64", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("árvíztűrő tükörfúrógép\r\n", 5)))},
+		{"quoted-printable", "=C3=A1rv=C3=ADzt=C5=B1r=C5=91 t=C3=BCk=C3=B6rf=C3=BAr=C3=B3g=C3=A9p\r\n"},
+	} {
+		msg := head
+		if tc.te != "" {
+			msg += "Content-Transfer-Encoding: " + tc.te + "\r\n"
+		}
+		msg += "\r\n" + tc.body
+
+		parse := func(s string) (MailPart, []byte, string) {
+			m, err := mail.ReadMessage(strings.NewReader(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var mp MailPart
+			var body []byte
+			var buf strings.Builder
+			if err := WalkMessage(m, func(p MailPart) error {
+				mp = p
+				if body, err = io.ReadAll(io.NewSectionReader(p.Body, 0, p.Body.Size())); err != nil {
+					return err
+				}
+				n, err := p.WriteTo(&buf)
+				if err == nil && n != int64(buf.Len()) {
+					t.Errorf("%q: WriteTo returned %d, wrote %d", tc.te, n, buf.Len())
+				}
+				return err
+			}, WalkOptions{}, nil); err != nil {
+				t.Fatal(err)
+			}
+			return mp, body, buf.String()
+		}
+		mp1, body1, wire1 := parse(msg)
+		mp2, body2, wire2 := parse(wire1)
+		if !bytes.Equal(body1, body2) {
+			t.Errorf("%q: body mismatch:\n%q\n%q", tc.te, body1, body2)
+		}
+		if wire1 != wire2 {
+			t.Errorf("%q: not stable:\n%q\n%q", tc.te, wire1, wire2)
+		}
+		if mp1.TransferEncoding != tc.te || mp2.TransferEncoding != tc.te {
+			t.Errorf("got transfer encodings %q, %q, wanted %q", mp1.TransferEncoding, mp2.TransferEncoding, tc.te)
+		}
+		if s1, s2 := mp1.Header.Get("Subject"), mp2.Header.Get("Subject"); s1 != "árvíztűrő" || s1 != s2 {
+			t.Errorf("%q: got subjects %q, %q", tc.te, s1, s2)
+		}
+		for _, mp := range []MailPart{mp1, mp2} {
+			addr, err := mail.ParseAddress(mp.Header.Get("From"))
+			if err != nil {
+				t.Fatalf("%q: parse From %q: %+v", tc.te, mp.Header.Get("From"), err)
+			}
+			if addr.Name != "Gulácsi Tamás" || addr.Address != "a@example.com" {
+				t.Errorf("%q: got From %+v", tc.te, addr)
+			}
+		}
+		if strings.Contains(wire1, "X-Hash") || strings.Contains(wire1, HashKeyName) {
+			t.Errorf("%q: internal headers are written:\n%s", tc.te, wire1)
+		}
+	}
+}
+
+func TestWriteToHeaders(t *testing.T) {
+	const msg = "Subject: =?UTF-8?Q?=C3=A1rv=C3=ADzt=C5=B1r=C5=91_t=C3=BCk=C3=B6rf=C3=BAr=C3=B3g=C3=A9p?= now\r\n" +
+		"To: =?UTF-8?Q?=C3=81rp=C3=A1d?= <b@example.com>, c@example.com\r\n" +
+		"From: a@example.com\r\nX-Zeta: z\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\nbody\r\n"
+	mp, err := NewMailPart(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if err = Walk(mp, func(mp MailPart) error {
+		_, err := mp.WriteTo(&buf)
+		return err
+	}, false); err != nil {
+		t.Fatal(err)
+	}
+	wire := buf.String()
+	head, _, _ := strings.Cut(wire, "\r\n\r\n")
+	var keys []string
+	for _, line := range strings.Split(head, "\r\n") {
+		k, _, _ := strings.Cut(line, ":")
+		keys = append(keys, k)
+	}
+	if got, want := strings.Join(keys, ","), "Subject,To,From,X-Zeta,Mime-Version,Content-Type"; got != want {
+		t.Errorf("got keys %s, wanted %s", got, want)
+	}
+	m, err := mail.ReadMessage(strings.NewReader(wire))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subj, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject")); err != nil || subj != "árvíztűrő tükörfúrógép now" {
+		t.Errorf("got Subject %q (%v)", subj, err)
+	}
+	to, err := m.Header.AddressList("To")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(to) != 2 || to[0].Name != "Árpád" || to[0].Address != "b@example.com" || to[1].Address != "c@example.com" {
+		t.Errorf("got To %+v", to)
+	}
+}
+
+func TestWalkLimits(t *testing.T) {
+	var buf strings.Builder
+	buf.WriteString("From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n")
+	for i := 0; i < 10; i++ {
+		buf.WriteString("--BB\r\nContent-Type: text/plain\r\n\r\n" + strings.Repeat("x", 100) + "\r\n")
+	}
+	buf.WriteString("--BB--\r\n")
+	msg := buf.String()
+	for _, tc := range []struct {
+		opts WalkOptions
+		want int
+		err  bool
+	}{
+		{WalkOptions{}, 10, false},
+		{WalkOptions{MaxParts: 20}, 10, false},
+		{WalkOptions{MaxParts: 5}, 4, true}, // the container is a part, too
+		{WalkOptions{MaxTotalBytes: int64(len(msg)) + 500}, 5, true},
+	} {
+		var n int
+		err := WalkWith(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+			func(mp MailPart) error { n++; return nil },
+			tc.opts)
+		if tc.err != errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%+v: got %+v", tc.opts, err)
+		}
+		if n != tc.want {
+			t.Errorf("%+v: got %d parts, wanted %d", tc.opts, n, tc.want)
+		}
+	}
+}
+
+func TestWalkDedup(t *testing.T) {
+	const attachment = "--BB\r\nContent-Type: application/octet-stream\r\nContent-Transfer-Encoding: base64\r\n\r\nQVRUQUNITUVOVA==\r\n"
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n" +
+		attachment +
+		"--BB\r\nContent-Type: message/rfc822\r\n\r\n" +
+		"From: b@example.com\r\nMIME-Version: 1.0\r\nContent-Type: application/octet-stream\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\nQVRUQUNITUVOVA==\r\n" +
+		attachment +
+		"--BB--\r\n"
+	for _, tc := range []struct {
+		mode       DedupMode
+		want, dups int
+	}{
+		{DedupNone, 4, 0},
+		{DedupMark, 4, 2},
+		{DedupSkip, 2, 0},
+	} {
+		var n, dups int
+		if err := WalkWith(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+			func(mp MailPart) error {
+				n++
+				if mp.Duplicate {
+					dups++
+				}
+				return nil
+			},
+			WalkOptions{Dedup: tc.mode},
+		); err != nil {
+			t.Fatal(err)
+		}
+		if n != tc.want || dups != tc.dups {
+			t.Errorf("%d: got %d parts (%d duplicates), wanted %d (%d)", tc.mode, n, dups, tc.want, tc.dups)
+		}
+	}
+}
+
+func TestCheckEncoding(t *testing.T) {
+	if CheckEncoding {
+		t.Error("CheckEncoding is on by default")
+	}
+	defer func(check bool) { CheckEncoding = check }(CheckEncoding)
+	for _, te := range []struct{ name, body string }{
+		{"base64", "QVRU*QUNITU!VOVA==\r\n"},
+		{"quoted-printable", "bad =XY escape\r\n"},
+		{"base64", "QVRUQUNITUVOVA== \r\njunk\r\n"},
+	} {
+		msg := "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+			"Content-Type: application/octet-stream\r\nContent-Transfer-Encoding: " + te.name + "\r\n\r\n" +
+			te.body
+		walk := func() error {
+			m, err := mail.ReadMessage(strings.NewReader(msg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return WalkMessage(m, func(mp MailPart) error {
+				_, err := io.Copy(io.Discard, mp.GetBody())
+				return err
+			}, WalkOptions{}, nil)
+		}
+		CheckEncoding = true
+		if err := walk(); !errors.Is(err, ErrBadEncoding) {
+			t.Errorf("%s: strict: got %+v, wanted ErrBadEncoding", te.name, err)
+		}
+		CheckEncoding = false
+		if err := walk(); err != nil {
+			t.Errorf("%s: lenient: %+v", te.name, err)
+		}
+	}
+}
+
+func TestFlattenHeaders(t *testing.T) {
+	hdr := map[string][]string{
+		"Received": {"from a", "from b"},
+		"subject":  {"=?UTF-8?Q?=C3=A1rv=C3=ADz?="},
+		"X-Empty":  {},
+	}
+	for _, tc := range []struct {
+		opts FlattenOptions
+		want map[string]string
+	}{
+		{FlattenOptions{}, map[string]string{"Received": "from a, from b", "Subject": "árvíz"}},
+		{FlattenOptions{LowerKeys: true, FirstOnly: true}, map[string]string{"received": "from a", "subject": "árvíz"}},
+	} {
+		got := FlattenHeaders(hdr, tc.opts)
+		if len(got) != len(tc.want) {
+			t.Errorf("%+v: got %q, wanted %q", tc.opts, got, tc.want)
+		}
+		for k, v := range tc.want {
+			if got[k] != v {
+				t.Errorf("%+v: %s: got %q, wanted %q", tc.opts, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestWalkVisitor(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"AA\"\r\n\r\n" +
+		"--AA\r\nContent-Type: multipart/alternative; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nplain\r\n" +
+		"--BB\r\nContent-Type: text/html\r\n\r\n<p>html</p>\r\n" +
+		"--BB--\r\n" +
+		"--AA\r\nContent-Type: application/pdf\r\n\r\nPDF\r\n" +
+		"--AA--\r\n"
+	var events []string
+	ev := func(prefix string) func(MailPart) error {
+		return func(mp MailPart) error {
+			events = append(events, prefix+strconv.Itoa(mp.Level)+":"+mp.ContentType)
+			return nil
+		}
+	}
+	if err := WalkVisitor(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+		Visitor{OnEnter: ev("+"), OnLeave: ev("-"), OnPart: ev(" ")},
+		WalkOptions{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"+0:",
+		"+1:multipart/mixed",
+		"+2:multipart/alternative",
+		" 3:text/plain", " 3:text/html",
+		"-2:multipart/alternative",
+		" 2:application/pdf",
+		"-1:multipart/mixed",
+		"-0:",
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwanted\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+
+	stop := errors.New("stop")
+	err := WalkVisitor(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+		Visitor{OnEnter: func(mp MailPart) error {
+			if mp.ContentType == "multipart/alternative" {
+				return stop
+			}
+			return nil
+		}},
+		WalkOptions{},
+	)
+	if !errors.Is(err, stop) {
+		t.Errorf("got %v, wanted %v", err, stop)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	const msg = "From: =?utf-8?q?Gul=C3=A1csi_Tam=C3=A1s?= <t@example.com>\r\n" +
+		"To: a@example.com, =?iso-8859-2?q?=C1rv=EDzt=FBr=F5?= <b@example.com>\r\n" +
+		"Cc: c@example.com\r\n" +
+		"Subject: =?utf-8?b?w6lsZXQ=?= is\r\n" +
+		"Date: Mon, 02 Jan 2006 15:04:05 +0700\r\n" +
+		"MIME-Version: 1.0\r\nContent-Type: text/plain\r\n\r\nbody\r\n"
+	check := func(t *testing.T, env Envelope) {
+		t.Helper()
+		if env.Subject != "élet is" {
+			t.Errorf("Subject: got %q", env.Subject)
+		}
+		if len(env.From) != 1 || env.From[0].Name != "Gulácsi Tamás" || env.From[0].Address != "t@example.com" {
+			t.Errorf("From: got %v", env.From)
+		}
+		if len(env.To) != 2 || env.To[0].Address != "a@example.com" || env.To[1].Name != "Árvíztűrő" {
+			t.Errorf("To: got %v", env.To)
+		}
+		if len(env.Cc) != 1 || env.Cc[0].Address != "c@example.com" {
+			t.Errorf("Cc: got %v", env.Cc)
+		}
+		if env.Date.Unix() != 1136189045 {
+			t.Errorf("Date: got %v", env.Date)
+		}
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := MailPart{Header: textproto.MIMEHeader(m.Header)}.Envelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, env)
+
+	if err = Walk(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+		func(mp MailPart) error {
+			// a single part message: the part is the message
+			env, err := mp.Envelope()
+			if err != nil {
+				return err
+			}
+			check(t, env)
+			return nil
+		},
+		false,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if env, err = (MailPart{Header: textproto.MIMEHeader{"To": {"<<bad"}}}).Envelope(); err == nil {
+		t.Errorf("wanted error, got %v", env)
+	}
+}
+
+func TestNewMailPartFromReaderAt(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: text/html\r\n\r\n<p>second</p>\r\n" +
+		"--BB--\r\n"
+	fn := filepath.Join(t.TempDir(), "a.eml")
+	if err := os.WriteFile(fn, []byte(msg), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fh, err := os.Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	fromFile := NewMailPartFromReaderAt(fh, int64(len(msg)))
+	fromReader, err := NewMailPart(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for i, part := range []MailPart{fromReader, fromFile} {
+		var got []string
+		if err := Walk(part, func(mp MailPart) error {
+			b, err := io.ReadAll(mp.GetBody())
+			got = append(got, mp.ContentType+":"+string(b))
+			return err
+		}, false); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			want = got
+		} else if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("got %q, wanted %q", got, want)
+		}
+	}
+	if len(want) != 2 {
+		t.Errorf("got %q", want)
+	}
+}
+
+func TestSaveAttachments(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nbody\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\nContent-Disposition: attachment; filename=\"a/b.txt\"\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: text/plain; name=\"a/b.txt\"\r\n\r\nsecond\r\n" +
+		"--BB\r\nContent-Type: image/png\r\nContent-Disposition: inline\r\nContent-ID: <logo>\r\n\r\nPNG\r\n" +
+		"--BB\r\nContent-Type: application/octet-stream\r\nContent-Disposition: attachment; filename=big.bin\r\n\r\n0123456789\r\n" +
+		"--BB--\r\n"
+	mp, err := NewMailPart(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	paths, err := mp.SaveAttachments(dir, SaveOptions{MaxSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.Base(p)+":"+string(b))
+	}
+	if want := []string{"a-b.txt:first", "a-b-1.txt:second"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	if paths, err = mp.SaveAttachments(dir, SaveOptions{Inline: true, OnCollision: CollisionSkip}); err != nil {
+		t.Fatal(err)
+	} else if len(paths) != 2 || !strings.HasSuffix(paths[0], ".png") || filepath.Base(paths[1]) != "big.bin" {
+		t.Errorf("got %q, wanted the inline image and big.bin", paths)
+	}
+	if _, err = mp.SaveAttachments(dir, SaveOptions{OnCollision: CollisionError}); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("got %v, wanted ErrExist", err)
+	}
+}
+
+func TestDeterministicSeq(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: message/rfc822\r\n\r\n" +
+		"Subject: inner\r\nContent-Type: text/plain\r\n\r\ninner\r\n" +
+		"--BB--\r\n"
+	var want string
+	for i := 0; i < 2; i++ {
+		mp, err := NewMailPart(strings.NewReader(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var seqs []string
+		if err = WalkWith(mp, func(mp MailPart) error {
+			seqs = append(seqs, strconv.Itoa(mp.Seq))
+			return nil
+		}, WalkOptions{DeterministicSeq: true}); err != nil {
+			t.Fatal(err)
+		}
+		got := strings.Join(seqs, ",")
+		if i == 0 {
+			want = got
+			if len(seqs) != 2 || seqs[0] != "2" {
+				t.Errorf("got %q, wanted 2 parts, the first numbered 2", got)
+			}
+		} else if got != want {
+			t.Errorf("second walk: got %q, wanted %q", got, want)
+		}
+	}
+}
+
+func TestDescendArchives(t *testing.T) {
+	var zipBuf, gzBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for _, f := range [][2]string{{"dir/a.txt", "alpha"}, {"b.html", "<p>beta</p>"}} {
+		w, err := zw.Create(f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(f[1]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(&gzBuf)
+	gw.Write([]byte("gamma"))
+	gw.Close()
+	msg := "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nbody\r\n" +
+		"--BB\r\nContent-Type: application/zip\r\nContent-Transfer-Encoding: base64\r\n\r\n" +
+		base64.StdEncoding.EncodeToString(zipBuf.Bytes()) + "\r\n" +
+		"--BB\r\nContent-Type: application/gzip\r\nContent-Disposition: attachment; filename=c.txt.gz\r\nContent-Transfer-Encoding: base64\r\n\r\n" +
+		base64.StdEncoding.EncodeToString(gzBuf.Bytes()) + "\r\n" +
+		"--BB--\r\n"
+	mp, err := NewMailPart(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	todo := func(mp MailPart) error {
+		b, err := io.ReadAll(mp.GetBody())
+		got = append(got, mp.FileName()+":"+mp.ContentType+":"+string(b))
+		return err
+	}
+	if err = WalkWith(mp, todo, WalkOptions{DescendArchives: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{":text/plain:body", "dir/a.txt:text/plain:alpha", "b.html:text/html:<p>beta</p>", "c.txt:text/plain:gamma"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	got = got[:0]
+	if err = WalkWith(mp, todo, WalkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !strings.Contains(got[1], ":application/zip:") {
+		t.Errorf("without DescendArchives: got %q", got)
+	}
+
+	if err = WalkWith(mp, todo, WalkOptions{DescendArchives: true, MaxArchiveBytes: 8}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got %v, wanted ErrLimitExceeded", err)
+	}
+}
+
+func TestCalendarEvents(t *testing.T) {
+	const ics = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nMETHOD:CANCEL\r\n" +
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Budapest\r\nEND:VTIMEZONE\r\n" +
+		"BEGIN:VEVENT\r\nUID:abc-1\r\nSEQUENCE:2\r\nSTATUS:CANCELLED\r\n" +
+		"SUMMARY:Heti \\, megbesz\r\n élés\r\n" +
+		"DTSTART;TZID=Europe/Budapest:20230405T100000\r\n" +
+		"DTEND:20230405T090000Z\r\n" +
+		"ORGANIZER;CN=\"Gulácsi, Tamás\":mailto:t@example.com\r\n" +
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;CN=Béla:MAILTO:b@example.com\r\n" +
+		"ATTENDEE:mailto:c@example.com\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:abc-2\r\nDTSTART;VALUE=DATE:20230406\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	msg := "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\ninvite\r\n" +
+		"--BB\r\nContent-Type: text/calendar; charset=utf-8; method=REQUEST\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" +
+		base64.StdEncoding.EncodeToString([]byte(ics)) + "\r\n" +
+		"--BB--\r\n"
+	var events []CalendarEvent
+	if err := Walk(MailPart{Body: io.NewSectionReader(strings.NewReader(msg), 0, int64(len(msg)))},
+		func(mp MailPart) error {
+			evs, err := mp.CalendarEvents()
+			events = append(events, evs...)
+			return err
+		},
+		false,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, wanted 2: %+v", len(events), events)
+	}
+	ev := events[0]
+	loc, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skip(err)
+	}
+	if ev.Method != "CANCEL" || ev.UID != "abc-1" || ev.Sequence != 2 || ev.Status != "CANCELLED" ||
+		ev.Summary != "Heti , megbeszélés" || ev.AllDay {
+		t.Errorf("got %+v", ev)
+	}
+	if want := time.Date(2023, 4, 5, 10, 0, 0, 0, loc); !ev.Start.Equal(want) || !ev.End.Equal(want.Add(time.Hour)) {
+		t.Errorf("got %s - %s, wanted %s", ev.Start, ev.End, want)
+	}
+	if ev.Organizer == nil || ev.Organizer.Name != "Gulácsi, Tamás" || ev.Organizer.Address != "t@example.com" {
+		t.Errorf("organizer: got %+v", ev.Organizer)
+	}
+	if len(ev.Attendees) != 2 || ev.Attendees[0].Name != "Béla" || ev.Attendees[0].Address != "b@example.com" ||
+		ev.Attendees[1].Address != "c@example.com" {
+		t.Errorf("attendees: got %v", ev.Attendees)
+	}
+	if ev = events[1]; !ev.AllDay || ev.Start.Day() != 6 || ev.Method != "CANCEL" {
+		t.Errorf("got %+v", ev)
+	}
+}
+
+func TestHasMIMEBoundaries(t *testing.T) {
+	for i, tc := range []struct {
+		Data, Prefix string
+		Want         bool
+	}{
+		{"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n--BB\r\n\r\nsecond\r\n--BB--\r\n", "", true},
+		{"preamble\n--BB  \nContent-Type: text/plain\n\nfirst\n--BB--", "", true},
+		{"--BB\r\nContent-Type: text/plain\r\n\r\nfirst\r\n--BB--\r\n", "----=_Part", false},
+		{"------=_Part_1\r\n\r\nfirst\r\n------=_Part_1--\r\n", "----=_Part", true},
+		{"--BB\r\nContent-Type: text/plain\r\n\r\nunclosed\r\n", "", false},
+		{"--BB\r\n\r\n--CC--\r\n", "", false},
+		{"-- \r\nsignature\r\n", "", false},
+		{"plain text\r\n", "", false},
+	} {
+		if got := HasMIMEBoundaries(strings.NewReader(tc.Data), int64(len(tc.Data)), tc.Prefix); got != tc.Want {
+			t.Errorf("%d. got %t, wanted %t", i, got, tc.Want)
+		}
+	}
+}
+
+func TestRawHeaders(t *testing.T) {
+	const msg = "X-Zeta: z\r\nFrom: a@example.com\r\nSubject: =?UTF-8?Q?=C3=A1rv=C3=ADz?=\r\n folded\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"preamble\r\n" +
+		"--BB\r\ncontent-type: text/plain\r\nX-A: 1\r\n\r\nfirst\r\n" +
+		"--BB\r\nContent-Type: message/rfc822\r\n\r\n" +
+		"Subject: inner\r\nContent-Type: text/plain\r\n\r\ninner\r\n" +
+		"--BB--\r\n"
+	mp, err := NewMailPart(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][][2]string
+	var parent [][2]string
+	if err = WalkWith(mp, func(mp MailPart) error {
+		if mp.Parent != nil && parent == nil {
+			parent = mp.Parent.RawHeaders()
+		}
+		got = append(got, mp.RawHeaders())
+		return nil
+	}, WalkOptions{KeepRawHeaders: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][][2]string{
+		{{"content-type", " text/plain"}, {"X-A", " 1"}},
+		{{"Subject", " inner"}, {"Content-Type", " text/plain"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	wantParent := [][2]string{
+		{"X-Zeta", " z"}, {"From", " a@example.com"},
+		{"Subject", " =?UTF-8?Q?=C3=A1rv=C3=ADz?=\r\n folded"},
+		{"MIME-Version", " 1.0"},
+		{"Content-Type", " multipart/mixed; boundary=\"BB\""},
+	}
+	if !reflect.DeepEqual(parent, wantParent) {
+		t.Errorf("got %q, wanted %q", parent, wantParent)
+	}
+
+	if err = WalkWith(mp, func(mp MailPart) error {
+		if h := mp.RawHeaders(); h != nil {
+			t.Errorf("got %q without KeepRawHeaders", h)
+		}
+		return nil
+	}, WalkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBodyThreshold(t *testing.T) {
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain\r\n\r\nthis body is longer than the threshold\r\n" +
+		"--BB--\r\n"
+	mp, err := NewMailPart(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// spilling to a missing directory fails
+	oldSpillDir := SpillDir
+	SpillDir = filepath.Join(t.TempDir(), "missing")
+	defer func() { SpillDir = oldSpillDir }()
+	todo := func(MailPart) error { return nil }
+	if err = WalkWith(mp, todo, WalkOptions{}); err != nil {
+		t.Errorf("default threshold: %+v", err)
+	}
+	if err = WalkWith(mp, todo, WalkOptions{BodyThreshold: 16}); err == nil {
+		t.Error("wanted spill error with BodyThreshold=16")
+	}
+}
+
+func TestDecodeCharset(t *testing.T) {
+	RegisterCharset("x-test-body-cp852", func(r io.Reader) io.Reader {
+		return charmap.CodePage852.NewDecoder().Reader(r)
+	})
+	const msg = "From: a@example.com\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BB\"\r\n\r\n" +
+		"--BB\r\nContent-Type: text/plain; charset=x-test-body-cp852\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n=B5rv=A1zt=FBr=8B\r\n" +
+		"--BB\r\nContent-Type: text/plain; charset=x-unknown\r\n\r\n\xb5rv\r\n" +
+		"--BB\r\nContent-Type: application/octet-stream; charset=x-test-body-cp852\r\n\r\n\xb5rv\r\n" +
+		"--BB--\r\n"
+	for _, decode := range []bool{false, true} {
+		mp, err := NewMailPart(strings.NewReader(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		if err = WalkWith(mp, func(mp MailPart) error {
+			b, err := io.ReadAll(mp.GetBody())
+			got = append(got, mp.MediaType["charset"]+" "+mp.Header.Get("Content-Type")+" "+strings.TrimSpace(string(b)))
+			return err
+		}, WalkOptions{DecodeCharset: decode}); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"x-test-body-cp852 text/plain; charset=x-test-body-cp852 \xb5rv\xa1zt\xfbr\x8b",
+			"x-unknown text/plain; charset=x-unknown \xb5rv",
+			"x-test-body-cp852 application/octet-stream; charset=x-test-body-cp852 \xb5rv",
+		}
+		if decode {
+			want[0] = "utf-8 text/plain; charset=utf-8 Árvíztűrő"
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DecodeCharset=%t: got %q, wanted %q", decode, got, want)
+		}
+	}
+
+	// not multipart
+	msg2 := "From: a@example.com\r\nContent-Type: text/plain; charset=x-test-body-cp852\r\n\r\n\xb5rv\r\n"
+	mp, err := NewMailPart(strings.NewReader(msg2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = WalkWith(mp, func(mp MailPart) error {
+		b, err := io.ReadAll(mp.GetBody())
+		if got := strings.TrimSpace(string(b)); got != "Árv" || mp.MediaType["charset"] != "utf-8" {
+			t.Errorf("got %q (%v)", got, mp.MediaType)
+		}
+		return err
+	}, WalkOptions{DecodeCharset: true}); err != nil {
+		t.Fatal(err)
+	}
+}
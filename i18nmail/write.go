@@ -0,0 +1,195 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+// Use of this source code is governed by an Apache 2.0
+// license that can be found in the LICENSE file.
+
+package i18nmail
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sloonz/go-qprintable"
+)
+
+// WriteTo writes the part in wire format: the Header and the Body,
+// re-encoded with the original TransferEncoding.
+//
+// The header fields are written in their original order (the ones added later, sorted, at the end),
+// without the headers added by Walk (X-Hash, HashKeyName, X-FileName).
+// Non-ASCII header values are written as RFC 2047 encoded-words:
+// only the display names of the address headers, and only the non-ASCII words of the others.
+func (mp MailPart) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	te := strings.ToLower(mp.TransferEncoding)
+	hdr := make(textproto.MIMEHeader, len(mp.Header)+1)
+	for k, vv := range mp.Header {
+		switch textproto.CanonicalMIMEHeaderKey(k) {
+		case "X-Hash", textproto.CanonicalMIMEHeaderKey(HashKeyName), "X-Filename": // internal, see Walk
+		default:
+			hdr[k] = vv
+		}
+	}
+	if te == "base64" || te == "quoted-printable" {
+		hdr.Set("Content-Transfer-Encoding", te)
+	}
+	for _, k := range mp.headerKeys(hdr) {
+		for _, v := range hdr[k] {
+			if !isASCII(v) {
+				v = encodeHeader(k, v)
+			}
+			bw.WriteString(k)
+			bw.WriteString(": ")
+			bw.WriteString(v)
+			bw.WriteString("\r\n")
+		}
+	}
+	bw.WriteString("\r\n")
+
+	var body io.Reader
+	if mp.Body != nil {
+		body = io.NewSectionReader(mp.Body, 0, mp.Body.Size())
+	} else {
+		body = strings.NewReader("")
+	}
+	var err error
+	switch te {
+	case "base64":
+		enc := base64.NewEncoder(base64.StdEncoding, &lineWriter{w: bw, width: 76})
+		if _, err = io.Copy(enc, body); err == nil {
+			err = enc.Close()
+		}
+		if err == nil {
+			_, err = bw.WriteString("\r\n")
+		}
+	case "quoted-printable":
+		br := bufio.NewReaderSize(body, 1024)
+		first, _ := br.Peek(1024)
+		qenc := qprintable.BinaryEncoding
+		if len(first) > 0 {
+			qenc = qprintable.DetectEncoding(string(first))
+		}
+		enc := qprintable.NewEncoderWithEOL("\r\n", qenc, bw)
+		if _, err = io.Copy(enc, br); err == nil {
+			err = enc.Close()
+		}
+	default:
+		_, err = io.Copy(bw, body)
+	}
+	if err != nil {
+		return cw.n, fmt.Errorf("write body: %w", err)
+	}
+	err = bw.Flush()
+	return cw.n, err
+}
+
+// headerKeys returns the keys of hdr in the original order, then the rest sorted.
+func (mp MailPart) headerKeys(hdr textproto.MIMEHeader) []string {
+	keys := make([]string, 0, len(hdr))
+	seen := make(map[string]struct{}, len(hdr))
+	for _, k := range mp.headerOrder {
+		if _, ok := hdr[k]; ok {
+			keys = append(keys, k)
+			seen[k] = struct{}{}
+		}
+	}
+	n := len(keys)
+	for k := range hdr {
+		if _, ok := seen[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[n:])
+	return keys
+}
+
+// encodeHeader returns the RFC 2047 encoded value of the header:
+// the address lists are re-formatted by mail.Address, encoding only the display names,
+// other values are encoded word by word (a run of non-ASCII words as one encoded-word,
+// to keep the spaces between them).
+func encodeHeader(key, value string) string {
+	switch key {
+	case "From", "To", "Cc", "Bcc", "Reply-To", "Sender":
+		if addrs, err := mail.ParseAddressList(value); err == nil {
+			ss := make([]string, len(addrs))
+			for i, a := range addrs {
+				ss[i] = a.String()
+			}
+			return strings.Join(ss, ", ")
+		}
+	}
+	words := strings.Split(value, " ")
+	out := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		if isASCII(words[i]) {
+			out = append(out, words[i])
+			continue
+		}
+		j := i + 1
+		for j < len(words) && !isASCII(words[j]) {
+			j++
+		}
+		out = append(out, mime.QEncoding.Encode("utf-8", strings.Join(words[i:j], " ")))
+		i = j - 1
+	}
+	return strings.Join(out, " ")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// lineWriter breaks the written stream into CRLF-terminated lines of width.
+type lineWriter struct {
+	w     io.Writer
+	width int
+	col   int
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	var n int
+	for len(p) != 0 {
+		if lw.col == lw.width {
+			if _, err := io.WriteString(lw.w, "\r\n"); err != nil {
+				return n, err
+			}
+			lw.col = 0
+		}
+		chunk := p
+		if len(chunk) > lw.width-lw.col {
+			chunk = chunk[:lw.width-lw.col]
+		}
+		m, err := lw.w.Write(chunk)
+		n += m
+		lw.col += m
+		if err != nil {
+			return n, err
+		}
+		p = p[m:]
+	}
+	return n, nil
+}
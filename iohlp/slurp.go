@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 
 	"github.com/tgulacsi/go/bufpool"
@@ -21,24 +22,91 @@ var srBufPool = bufpool.New(1 << 20)
 // If the read length is below the threshold, then the bytes are read into memory;
 // otherwise, a temp file is created, and mmap-ed.
 func MakeSectionReader(r io.Reader, threshold int) (*io.SectionReader, error) {
+	return MakeSectionReaderAt(r, threshold, "")
+}
+
+// MakeSectionReaderAt is like MakeSectionReader, but the temp file is created in dir.
+//
+// If dir is the empty string, the default temp directory is used (see os.TempDir).
+func MakeSectionReaderAt(r io.Reader, threshold int, dir string) (*io.SectionReader, error) {
+	sr, _, err := MakeSectionReaderResult(r, threshold, dir)
+	return sr, err
+}
+
+// ReadResult describes how MakeSectionReaderResult read its input.
+type ReadResult struct {
+	// Size is the number of bytes read.
+	Size int64
+	// Spilled is true if the data has been written to a temp file.
+	Spilled bool
+}
+
+// MakeSectionReaderResult is like MakeSectionReaderAt, but also returns
+// the number of bytes read and whether they have been spilled to a temp file.
+func MakeSectionReaderResult(r io.Reader, threshold int, dir string) (*io.SectionReader, ReadResult, error) {
+	sr, _, res, err := makeSectionReader(r, threshold, dir)
+	return sr, res, err
+}
+
+// SectionReadCloser is an io.SectionReader which can be closed,
+// releasing the mmap and the temp file backing it, if it has spilled to disk.
+type SectionReadCloser struct {
+	*io.SectionReader
+	closer io.Closer
+}
+
+// Close releases the resources.
+//
+// Must not be called concurrently with the reading methods.
+func (src *SectionReadCloser) Close() error {
+	if src == nil || src.closer == nil {
+		return nil
+	}
+	c := src.closer
+	src.closer = nil
+	return c.Close()
+}
+
+// MakeSectionReadCloser is like MakeSectionReaderAt, but the result must be closed,
+// to release the mmap and delete the temp file deterministically,
+// instead of waiting for the finalizer.
+func MakeSectionReadCloser(r io.Reader, threshold int, dir string) (*SectionReadCloser, ReadResult, error) {
+	sr, closer, res, err := makeSectionReader(r, threshold, dir)
+	if sr == nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, res, err
+	}
+	return &SectionReadCloser{SectionReader: sr, closer: closer}, res, err
+}
+
+func makeSectionReader(r io.Reader, threshold int, dir string) (*io.SectionReader, io.Closer, ReadResult, error) {
 	if rat, ok := r.(*io.SectionReader); ok {
-		return rat, nil
+		return rat, nil, ReadResult{Size: rat.Size()}, nil
 	}
 	buf := srBufPool.Get()
 	defer srBufPool.Put(buf)
 	_, err := io.CopyN(buf, r, int64(threshold)+1)
-	bsr := io.NewSectionReader(bytes.NewReader(buf.Bytes()), 1, int64(buf.Len()))
-	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
-		return bsr, fmt.Errorf("read below threshold: %w", err)
+	if err != nil {
+		// buf goes back to the pool
+		b := append(make([]byte, 0, buf.Len()), buf.Bytes()...)
+		bsr := io.NewSectionReader(bytes.NewReader(b), 0, int64(len(b)))
+		res := ReadResult{Size: int64(len(b))}
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return bsr, nil, res, fmt.Errorf("read below threshold: %w", err)
+		}
+		return bsr, nil, res, nil
 	}
-	fh, err := os.CreateTemp("", "iohlp-readall-")
+	fh, err := os.CreateTemp(dir, "iohlp-readall-")
 	if err != nil {
-		return bsr, fmt.Errorf("create temp file: %w", err)
+		return nil, nil, ReadResult{}, fmt.Errorf("create temp file: %w", err)
 	}
-	defer os.Remove(fh.Name())
+	fn := fh.Name()
+	defer os.Remove(fn)
 	defer fh.Close()
 	if _, err = fh.Write(buf.Bytes()); err != nil {
-		return bsr, fmt.Errorf("write temp file: %w", err)
+		return nil, nil, ReadResult{}, fmt.Errorf("write temp file: %w", err)
 	}
 	buf.Truncate(0)
 	_, err = io.Copy(fh, r)
@@ -53,7 +121,15 @@ func MakeSectionReader(r io.Reader, threshold int) (*io.SectionReader, error) {
 		err = closeErr
 	}
 	if mmapErr != nil {
-		return nil, mmapErr
+		return nil, nil, ReadResult{}, mmapErr
 	}
-	return io.NewSectionReader(rat, 0, int64(rat.Len())), err
+	closer := CloserFunc(func() error {
+		err := rat.Close()
+		// the file may be still there, if it couldn't be removed while mapped (Windows)
+		if rmErr := os.Remove(fn); rmErr != nil && err == nil && !errors.Is(rmErr, fs.ErrNotExist) {
+			err = rmErr
+		}
+		return err
+	})
+	return io.NewSectionReader(rat, 0, int64(rat.Len())), closer, ReadResult{Size: int64(rat.Len()), Spilled: true}, err
 }
@@ -6,6 +6,8 @@ package iohlp
 
 import (
 	"io"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -92,3 +94,87 @@ func (r *dummyReader) Read(p []byte) (int, error) {
 	}
 	return n, nil
 }
+
+func TestMakeSectionReaderResult(t *testing.T) {
+	for _, tc := range []struct {
+		in        string
+		threshold int
+		spilled   bool
+	}{
+		{"abraca dabra", 3, true},
+		{"abraca dabra", 12, false},
+		{"", 0, false},
+	} {
+		sr, res, err := MakeSectionReaderResult(strings.NewReader(tc.in), tc.threshold, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Spilled != tc.spilled || res.Size != int64(len(tc.in)) {
+			t.Errorf("%q/%d: got %+v", tc.in, tc.threshold, res)
+		}
+		b, err := io.ReadAll(sr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.in {
+			t.Errorf("got %q, wanted %q", b, tc.in)
+		}
+	}
+}
+
+func TestMakeSectionReadCloser(t *testing.T) {
+	dir := t.TempDir()
+	const want = "abraca dabra"
+	src, res, err := MakeSectionReadCloser(strings.NewReader(want), 3, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Spilled {
+		t.Errorf("not spilled: %+v", res)
+	}
+	b, err := io.ReadAll(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("got %q, wanted %q", b, want)
+	}
+	if err = src.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = src.Close(); err != nil {
+		t.Errorf("second Close: %+v", err)
+	}
+	if des, _ := os.ReadDir(dir); len(des) != 0 {
+		t.Errorf("temp dir is not empty: %v", des)
+	}
+}
+
+// Small reads below the threshold stay in memory (no temp file is created
+// - dir does not exist), start at the first byte,
+// and do not share the pooled buffer.
+func TestMakeSectionReaderSmall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	first, err := MakeSectionReaderAt(strings.NewReader("abc"), 16, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Size() != 3 {
+		t.Errorf("size: got %d, wanted 3", first.Size())
+	}
+	b := make([]byte, 1)
+	if _, err = first.ReadAt(b, 0); err != nil {
+		t.Fatal(err)
+	}
+	if b[0] != 'a' {
+		t.Errorf("first byte: got %q, wanted 'a'", b[0])
+	}
+	if _, err = MakeSectionReaderAt(strings.NewReader("xyz"), 16, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(io.NewSectionReader(first, 0, first.Size())); err != nil {
+		t.Fatal(err)
+	} else if string(got) != "abc" {
+		t.Errorf("after reuse: got %q, wanted %q", got, "abc")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+// Alignment is the horizontal alignment of the text of a Cell.
+type Alignment uint8
+
+const (
+	// AlignDefault aligns by the value type (numbers right, text left), or as the cell style says.
+	AlignDefault = Alignment(iota)
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// textAlign returns the fo:text-align of the Alignment.
+func (a Alignment) textAlign() string {
+	switch a {
+	case AlignLeft:
+		return "start"
+	case AlignCenter:
+		return "center"
+	case AlignRight:
+		return "end"
+	}
+	return ""
+}
+
+func (a Alignment) String() string {
+	switch a {
+	case AlignLeft:
+		return "left"
+	case AlignCenter:
+		return "center"
+	case AlignRight:
+		return "right"
+	}
+	return "default"
+}
+
+// alignStyle is a table-cell style with alignment and wrapping,
+// of a registered number format (see RegisterFormat), or without a format.
+type alignStyle struct {
+	Format string
+	Align  Alignment
+	Wrap   bool
+}
+
+// name of the style: "AA-center-wrap", "AA-right-accounting".
+func (as alignStyle) name() string {
+	name := "AA-"
+	if as.Align != AlignDefault {
+		name += as.Align.String()
+		if as.Wrap {
+			name += "-wrap"
+		}
+	} else {
+		name += "wrap"
+	}
+	if as.Format != "" {
+		name += "-" + as.Format
+	}
+	return name
+}
+
+// UseRows declares the alignment styles of the cells (with Align or Wrap) of the rows,
+// as the automatic styles are written before the rows.
+//
+// StreamFlat does this by itself, but for NewWriterOptions
+// call it with the rows (or a representative sample) to be written.
+func (as *AutomaticStyles) UseRows(rows ...Row) {
+	for _, row := range rows {
+		for _, c := range row.Cells {
+			st, ok := c.alignStyle()
+			if !ok {
+				continue
+			}
+			var found bool
+			for _, a := range as.alignStyles {
+				if found = a == st; found {
+					break
+				}
+			}
+			if !found {
+				// a new slice, as the AutomaticStyles may be a copy
+				as.alignStyles = append(as.alignStyles[:len(as.alignStyles):len(as.alignStyles)], st)
+			}
+		}
+	}
+}
+
+// styleName returns the style of the cell: the matching alignStyle if it has one,
+// otherwise its Style.
+func (c Cell) styleName() string {
+	if as, ok := c.alignStyle(); ok {
+		return as.name()
+	}
+	return c.Style
+}
+
+// alignStyle returns the alignStyle of the cell, if it has Align or Wrap,
+// and its Style is empty or a registered format.
+//
+// For other styles, Align and Wrap are ignored, as an automatic style
+// cannot be the parent of another.
+func (c Cell) alignStyle() (alignStyle, bool) {
+	if c.Align == AlignDefault && !c.Wrap {
+		return alignStyle{}, false
+	}
+	if c.Style != "" {
+		formatsMu.RLock()
+		_, ok := formats[c.Style]
+		formatsMu.RUnlock()
+		if !ok {
+			return alignStyle{}, false
+		}
+	}
+	return alignStyle{Format: c.Style, Align: c.Align, Wrap: c.Wrap}, true
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CellFor returns an unstyled Cell for the value, with the Type derived from the value's type:
+// bool is BoolType, the integers are IntType, the floats are FloatType, time.Time is DateType,
+// and everything else is a StringType (nil is an empty string).
+//
+// The Value is formatted as the office:value of the Type requires.
+func CellFor(v any) Cell {
+	switch x := v.(type) {
+	case nil:
+		return Cell{Type: StringType}
+	case string:
+		return Cell{Value: x, Type: StringType}
+	case []byte:
+		return Cell{Value: string(x), Type: StringType}
+	case bool:
+		return Cell{Value: strconv.FormatBool(x), Type: BoolType}
+	case int:
+		return Cell{Value: strconv.FormatInt(int64(x), 10), Type: IntType}
+	case int8:
+		return Cell{Value: strconv.FormatInt(int64(x), 10), Type: IntType}
+	case int16:
+		return Cell{Value: strconv.FormatInt(int64(x), 10), Type: IntType}
+	case int32:
+		return Cell{Value: strconv.FormatInt(int64(x), 10), Type: IntType}
+	case int64:
+		return Cell{Value: strconv.FormatInt(x, 10), Type: IntType}
+	case uint:
+		return Cell{Value: strconv.FormatUint(uint64(x), 10), Type: IntType}
+	case uint8:
+		return Cell{Value: strconv.FormatUint(uint64(x), 10), Type: IntType}
+	case uint16:
+		return Cell{Value: strconv.FormatUint(uint64(x), 10), Type: IntType}
+	case uint32:
+		return Cell{Value: strconv.FormatUint(uint64(x), 10), Type: IntType}
+	case uint64:
+		return Cell{Value: strconv.FormatUint(x, 10), Type: IntType}
+	case float32:
+		return Cell{Value: strconv.FormatFloat(float64(x), 'f', -1, 32), Type: FloatType}
+	case float64:
+		return Cell{Value: strconv.FormatFloat(x, 'f', -1, 64), Type: FloatType}
+	case time.Time:
+		if x.IsZero() {
+			return Cell{Type: StringType}
+		}
+		if h, m, s := x.Clock(); h == 0 && m == 0 && s == 0 && x.Nanosecond() == 0 {
+			return Cell{Value: x.Format("2006-01-02"), Type: DateType}
+		}
+		return Cell{Value: x.Format("2006-01-02T15:04:05"), Type: DateType}
+	case fmt.Stringer:
+		return Cell{Value: x.String(), Type: StringType}
+	default:
+		return Cell{Value: fmt.Sprint(v), Type: StringType}
+	}
+}
+
+// NewRowAny returns a Row of unstyled cells for the values, see CellFor.
+func NewRowAny(values ...any) Row {
+	row := Row{Cells: make([]Cell, len(values))}
+	for i, v := range values {
+		row.Cells[i] = CellFor(v)
+	}
+	return row
+}
+
+// FloatCell returns a FloatType Cell of v, with the style
+// (for example "accounting", see RegisterFormat).
+func FloatCell(v float64, style string) Cell {
+	return Cell{Value: strconv.FormatFloat(v, 'f', -1, 64), Type: FloatType, Style: style}
+}
+
+// The layouts of the displayed text of DateCell and DateTimeCell.
+var (
+	DateLayout     = "2006.01.02."
+	DateTimeLayout = "2006.01.02. 15:04:05"
+)
+
+// DateCell returns a DateType Cell of the date of t (as "2006-01-02"),
+// displaying it with DateLayout.
+//
+// ODF dates have no time zone: the date is taken in t.Location(),
+// so convert t (with t.UTC(), t.Local() or t.In(loc)) to choose.
+func DateCell(t time.Time) Cell {
+	return Cell{Value: t.Format("2006-01-02"), Text: t.Format(DateLayout), Type: DateType}
+}
+
+// DateTimeCell returns a DateType Cell of t (as "2006-01-02T15:04:05"),
+// displaying it with DateTimeLayout.
+//
+// ODF date-times have no time zone: the wall clock of t.Location() is stored,
+// so convert t (with t.UTC(), t.Local() or t.In(loc)) to choose.
+func DateTimeCell(t time.Time) Cell {
+	return Cell{Value: t.Format("2006-01-02T15:04:05"), Text: t.Format(DateTimeLayout), Type: DateType}
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxColumnChars is the maximum column width (in characters) of AutoSizeColumns.
+	MaxColumnChars = 80
+	// minColumnChars is the minimum column width of AutoSizeColumns.
+	minColumnChars = 4
+
+	// charWidthCm is the estimated width of a character of the default (10pt) font.
+	charWidthCm = 0.21
+	// columnPaddingCm is added to the width of the text.
+	columnPaddingCm = 0.2
+)
+
+// AutoSizeColumns sets the ColumnWidths from the longest value (line) in each column,
+// of the Heading and the given rows, as a monospace-ish estimate.
+// This way the widths are right in viewers which don't compute the optimal column width.
+//
+// Call it with the rows to be written (or a representative sample) before Begin.
+func (t *Table) AutoSizeColumns(rows ...Row) {
+	widths := t.ColumnWidths[:0]
+	measure := func(row Row) {
+		var pos int
+		for _, cell := range row.Cells {
+			if cell.ColIndex > 0 && cell.ColIndex-1 > pos {
+				pos = cell.ColIndex - 1
+			}
+			for len(widths) <= pos {
+				widths = append(widths, minColumnChars)
+			}
+			if w := textWidth(cell.Value); w > widths[pos] {
+				widths[pos] = w
+			}
+			pos++
+		}
+	}
+	measure(t.Heading)
+	for _, row := range rows {
+		measure(row)
+	}
+	for i, w := range widths {
+		if w > MaxColumnChars {
+			widths[i] = MaxColumnChars
+		}
+	}
+	t.ColumnWidths = widths
+	if t.ColCount < len(widths) {
+		t.ColCount = len(widths)
+	}
+}
+
+// defaultColumnStyle is the table-column style of the columns of a Table without Style.
+const defaultColumnStyle = "ACOL-0"
+
+// restColumns returns the style and the number of the columns not covered by ColumnWidths,
+// to declare all the ColCount columns (but at least one, as a table must have a column).
+func (t Table) restColumns() (string, int) {
+	n := t.ColCount - len(t.ColumnWidths)
+	if n <= 0 {
+		if len(t.ColumnWidths) != 0 {
+			return "", 0
+		}
+		n = 1
+	}
+	if t.Style != "" {
+		return t.Style, n
+	}
+	return defaultColumnStyle, n
+}
+
+// textWidth returns the length of the longest line of s, in characters.
+func textWidth(s string) int {
+	var w int
+	for _, line := range strings.Split(s, "\n") {
+		if n := utf8.RuneCountInString(line); n > w {
+			w = n
+		}
+	}
+	return w
+}
+
+// columnWidthStyle returns the name of the table-column style for the width (in characters).
+// Only even widths have a style, so the width is rounded up (see columnWidthChars).
+func columnWidthStyle(chars int) string {
+	return "ACOL-W" + strconv.Itoa(columnWidthChars(chars))
+}
+
+// columnWidthChars returns the width rounded up to even, between 2 and MaxColumnChars.
+func columnWidthChars(chars int) int {
+	if chars < 2 {
+		chars = 2
+	} else if chars > MaxColumnChars {
+		chars = MaxColumnChars
+	}
+	return (chars + 1) / 2 * 2
+}
+
+// UseTables declares the column styles of the ColumnWidths of the tables,
+// as the automatic styles are written before the tables.
+//
+// StreamFlat does this by itself, but for NewWriterOptions
+// call it with the tables (after AutoSizeColumns) to be written.
+func (as *AutomaticStyles) UseTables(tables ...Table) {
+	for _, t := range tables {
+		for _, w := range t.ColumnWidths {
+			w = columnWidthChars(w)
+			i := sort.SearchInts(as.columnWidths, w)
+			if i < len(as.columnWidths) && as.columnWidths[i] == w {
+				continue
+			}
+			// a new slice, as the AutomaticStyles may be a copy
+			as.columnWidths = append(as.columnWidths[:i:i], append([]int{w}, as.columnWidths[i:]...)...)
+		}
+	}
+}
+
+func columnWidthCm(chars int) float64 { return columnPaddingCm + float64(chars)*charWidthCm }
@@ -5,73 +5,302 @@
 package ods
 
 //line src/github.com/tgulacsi/go/ods/content.xml.qtpl:1
-import "strings"
+import "strconv"
 
 //line src/github.com/tgulacsi/go/ods/content.xml.qtpl:2
+import "strings"
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:3
 import "encoding/xml"
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:5
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:9
 import (
 	qtio422016 "io"
 
 	qt422016 "github.com/valyala/quicktemplate"
 )
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:5
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:9
 var (
 	_ = qtio422016.Copy
 	_ = qt422016.AcquireByteBuffer
 )
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:5
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:9
 func StreamXML(qw422016 *qt422016.Writer, s string) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:7
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
 	var buf strings.Builder
 	_ = xml.EscapeText(&buf, []byte(s))
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:10
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:14
 	qw422016.N().S(buf.String())
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 func WriteXML(qq422016 qtio422016.Writer, s string) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	StreamXML(qw422016, s)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	qt422016.ReleaseWriter(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 func XML(s string) string {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	qb422016 := qt422016.AcquireByteBuffer()
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	WriteXML(qb422016, s)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	qs422016 := string(qb422016.B)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	qt422016.ReleaseByteBuffer(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 	return qs422016
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:11
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:15
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:14
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
 func StreamBeginSheets(qw422016 *qt422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:14
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	StreamBeginSheetsWith(qw422016, DefaultCalcSettings)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+func WriteBeginSheets(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	StreamBeginSheets(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+func BeginSheets() string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	WriteBeginSheets(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:18
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+func StreamBeginSheetsWith(qw422016 *qt422016.Writer, cs CalcSettings) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	StreamBeginSheetsWithStyles(qw422016, cs, AutomaticStyles{Defaults: true})
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+func WriteBeginSheetsWith(qq422016 qtio422016.Writer, cs CalcSettings) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	StreamBeginSheetsWith(qw422016, cs)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+func BeginSheetsWith(cs CalcSettings) string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	WriteBeginSheetsWith(qb422016, cs)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:20
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:28
+func StreamBeginSheetsWithStyles(qw422016 *qt422016.Writer, cs CalcSettings, as AutomaticStyles) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:28
 	qw422016.N().S(`<?xml version="1.0" encoding="UTF-8"?>
 
 <office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:xlink="http://www.w3.org/1999/xlink" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0" xmlns:chart="urn:oasis:names:tc:opendocument:xmlns:chart:1.0" xmlns:dr3d="urn:oasis:names:tc:opendocument:xmlns:dr3d:1.0" xmlns:config="urn:oasis:names:tc:opendocument:xmlns:config:1.0" xmlns:math="http://www.w3.org/1998/Math/MathML" xmlns:form="urn:oasis:names:tc:opendocument:xmlns:form:1.0" xmlns:script="urn:oasis:names:tc:opendocument:xmlns:script:1.0" xmlns:ooo="http://openoffice.org/2004/office" xmlns:ooow="http://openoffice.org/2004/writer" xmlns:oooc="http://openoffice.org/2004/calc" xmlns:tableooo="http://openoffice.org/2009/table" xmlns:of="urn:oasis:names:tc:opendocument:xmlns:of:1.2" xmlns:dom="http://www.w3.org/2001/xml-events" xmlns:xforms="http://www.w3.org/2002/xforms" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:gnm="http://www.gnumeric.org/odf-extension/1.0" xmlns:css3t="http://www.w3.org/TR/css3-text/" xmlns:loext="urn:org:documentfoundation:names:experimental:office:xmlns:loext:1.0" xmlns:calcext="urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0" office:version="1.2">
   <office:scripts/>
   <office:font-face-decls/>
   <office:automatic-styles>
-    <style:style style:name="ta-0" style:family="table" style:master-page-name="ta-mp-0">
+    `)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:34
+	StreamContentAutomaticStyles(qw422016, as)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:34
+	qw422016.N().S(`
+  </office:automatic-styles>
+  <office:body>
+    <office:spreadsheet>
+      `)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:38
+	StreamCalculationSettings(qw422016, cs)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:38
+	qw422016.N().S(`
+`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+func WriteBeginSheetsWithStyles(qq422016 qtio422016.Writer, cs CalcSettings, as AutomaticStyles) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	StreamBeginSheetsWithStyles(qw422016, cs, as)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+func BeginSheetsWithStyles(cs CalcSettings, as AutomaticStyles) string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	WriteBeginSheetsWithStyles(qb422016, cs, as)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:39
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:41
+func StreamContentAutomaticStyles(qw422016 *qt422016.Writer, as AutomaticStyles) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:41
+	qw422016.N().S(`<style:style style:name="ta-0" style:family="table" style:master-page-name="ta-mp-0">
       <style:table-properties table:display="true" style:writing-mode="lr-tb"/>
     </style:style>
+`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:44
+	if as.Defaults {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:44
+		StreamDefaultAutomaticStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:44
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:44
+	qw422016.N().S(`
+    `)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:45
+	qw422016.N().S(as.Custom)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:45
+	qw422016.N().S(`
+    `)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:46
+	StreamNumberStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:46
+	StreamColumnWidthStyles(qw422016, as.columnWidths)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:46
+	StreamAlignStyles(qw422016, as.alignStyles)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:46
+	qw422016.N().S(`
+`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+func WriteContentAutomaticStyles(qq422016 qtio422016.Writer, as AutomaticStyles) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	StreamContentAutomaticStyles(qw422016, as)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+func ContentAutomaticStyles(as AutomaticStyles) string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	WriteContentAutomaticStyles(qb422016, as)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:47
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+func StreamCalculationSettings(qw422016 *qt422016.Writer, cs CalcSettings) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.N().S(`<table:calculation-settings table:null-year="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.N().D(cs.nullYear())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.N().S(`" table:automatic-find-labels="false" table:case-sensitive="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.E().V(cs.CaseSensitive)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.N().S(`" table:precision-as-shown="false" table:search-criteria-must-apply-to-whole-cell="true" table:use-regular-expressions="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.E().V(cs.UseRegularExpressions)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.N().S(`" table:use-wildcards="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.E().V(cs.UseWildcards)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:49
+	qw422016.N().S(`">
+        <table:null-date table:date-value="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:50
+	StreamXML(qw422016, cs.nullDate())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:50
+	qw422016.N().S(`" table:value-type="date"/>
+        <table:iteration table:maximum-difference="0.001" table:status="enable" table:steps="100"/>
+      </table:calculation-settings>
+`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+func WriteCalculationSettings(qq422016 qtio422016.Writer, cs CalcSettings) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	StreamCalculationSettings(qw422016, cs)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+func CalculationSettings(cs CalcSettings) string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	WriteCalculationSettings(qb422016, cs)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:53
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:55
+func StreamDefaultAutomaticStyles(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:55
+	qw422016.N().S(`
     <style:style style:name="AC-weight100" style:family="text">
       <style:text-properties fo:font-weight="100"/>
     </style:style>
@@ -184,288 +413,761 @@ func StreamBeginSheets(qw422016 *qt422016.Writer) {
       <style:table-row-properties style:row-height="13.5pt" style:use-optimal-row-height="true"/>
     </style:style>
     <style:style style:name="AROW-2" style:family="table-row"/>
-  </office:automatic-styles>
-  <office:body>
-    <office:spreadsheet>
-      <table:calculation-settings table:null-year="1930" table:automatic-find-labels="false" table:case-sensitive="false" table:precision-as-shown="false" table:search-criteria-must-apply-to-whole-cell="true" table:use-regular-expressions="false" table:use-wildcards="false">
-        <table:null-date table:date-value="1899-12-30" table:value-type="date"/>
-        <table:iteration table:maximum-difference="0.001" table:status="enable" table:steps="100"/>
-      </table:calculation-settings>
 `)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
-func WriteBeginSheets(qq422016 qtio422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+func WriteDefaultAutomaticStyles(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
-	StreamBeginSheets(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+	StreamDefaultAutomaticStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
 	qt422016.ReleaseWriter(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
-func BeginSheets() string {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+func DefaultAutomaticStyles() string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
 	qb422016 := qt422016.AcquireByteBuffer()
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
-	WriteBeginSheets(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+	WriteDefaultAutomaticStyles(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:168
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:171
+func StreamColumnWidthStyles(qw422016 *qt422016.Writer, widths []int) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:172
+	for _, w := range widths {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:172
+		qw422016.N().S(`<style:style style:name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:173
+		qw422016.E().S(columnWidthStyle(w))
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:173
+		qw422016.N().S(`" style:family="table-column"><style:table-column-properties style:column-width="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:174
+		qw422016.E().S(strconv.FormatFloat(columnWidthCm(w), 'f', 2, 64))
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:174
+		qw422016.N().S(`cm"/></style:style>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:176
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+func WriteColumnWidthStyles(qq422016 qtio422016.Writer, widths []int) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	StreamColumnWidthStyles(qw422016, widths)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+func ColumnWidthStyles(widths []int) string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	WriteColumnWidthStyles(qb422016, widths)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:177
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:181
+func StreamAlignStyles(qw422016 *qt422016.Writer, styles []alignStyle) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:182
+	for _, as := range styles {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:182
+		qw422016.N().S(`<style:style style:name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:183
+		StreamXML(qw422016, as.name())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:183
+		qw422016.N().S(`" style:family="table-cell" style:parent-style-name="Gnumeric-default"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:184
+		if as.Format != "" {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:184
+			qw422016.N().S(` `)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:184
+			qw422016.N().S(`style:data-style-name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:184
+			StreamXML(qw422016, namedFormat{Name: as.Format}.dataStyleName())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:184
+			qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:184
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:184
+		qw422016.N().S(`><style:table-cell-properties fo:wrap-option="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:185
+		if as.Wrap {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:185
+			qw422016.N().S(`wrap`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:185
+		} else {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:185
+			qw422016.N().S(`no-wrap`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:185
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:185
+		qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:186
+		if as.Align != AlignDefault {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:186
+			qw422016.N().S(` `)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:186
+			qw422016.N().S(`style:text-align-source="fix"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:186
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:186
+		qw422016.N().S(`/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:187
+		if as.Align != AlignDefault {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:187
+			qw422016.N().S(`<style:paragraph-properties fo:text-align="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:187
+			qw422016.E().S(as.Align.textAlign())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:187
+			qw422016.N().S(`"/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:187
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:187
+		qw422016.N().S(`</style:style>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:189
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+func WriteAlignStyles(qq422016 qtio422016.Writer, styles []alignStyle) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	StreamAlignStyles(qw422016, styles)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+func AlignStyles(styles []alignStyle) string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	WriteAlignStyles(qb422016, styles)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:190
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+func StreamNumberStyles(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	for _, nf := range registeredFormats() {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+		nf.StreamXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+func WriteNumberStyles(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	StreamNumberStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+func NumberStyles() string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	WriteNumberStyles(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:193
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:196
+func (nf namedFormat) streamnumberXML(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:196
+	qw422016.N().S(`<number:number number:decimal-places="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.N().D(nf.Decimals)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.N().S(`" number:min-decimal-places="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.N().D(nf.Decimals)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.N().S(`" number:min-integer-digits="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.N().D(nf.minIntegerDigits())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.N().S(`" number:grouping="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.E().V(nf.Grouping)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:197
+	qw422016.N().S(`"/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+func (nf namedFormat) writenumberXML(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	nf.streamnumberXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+func (nf namedFormat) numberXML() string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	nf.writenumberXML(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:198
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:200
+func (nf namedFormat) StreamXML(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:201
+	if nf.NegativeRed || nf.NegativeParens {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:201
+		qw422016.N().S(`<number:number-style style:name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:202
+		StreamXML(qw422016, nf.dataStyleName())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:202
+		qw422016.N().S(`-P0"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:202
+		qw422016.N().S(nf.localeAttrs())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:202
+		qw422016.N().S(`style:volatile="true">`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:202
+		nf.streamnumberXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:202
+		qw422016.N().S(`</number:number-style><number:number-style style:name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:203
+		StreamXML(qw422016, nf.dataStyleName())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:203
+		qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:203
+		qw422016.N().S(nf.localeAttrs())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:203
+		qw422016.N().S(`>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:204
+		if nf.NegativeRed {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:204
+			qw422016.N().S(`<style:text-properties fo:color="#ff0000"/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:204
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:205
+		if nf.NegativeParens {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:205
+			qw422016.N().S(`<number:text>(</number:text>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:207
+			nf.streamnumberXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:207
+			qw422016.N().S(`<number:text>)</number:text>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:209
+		} else {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:209
+			qw422016.N().S(`<number:text>-</number:text>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:211
+			nf.streamnumberXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:212
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:212
+		qw422016.N().S(`<style:map style:condition="value()&gt;=0" style:apply-style-name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:213
+		StreamXML(qw422016, nf.dataStyleName())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:213
+		qw422016.N().S(`-P0"/></number:number-style>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:215
+	} else {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:215
+		qw422016.N().S(`<number:number-style style:name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:216
+		StreamXML(qw422016, nf.dataStyleName())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:216
+		qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:216
+		qw422016.N().S(nf.localeAttrs())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:216
+		qw422016.N().S(`>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:216
+		nf.streamnumberXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:216
+		qw422016.N().S(`</number:number-style>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:217
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:217
+	qw422016.N().S(`<style:style style:name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:218
+	StreamXML(qw422016, nf.Name)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:218
+	qw422016.N().S(`" style:family="table-cell" style:parent-style-name="Gnumeric-default" style:data-style-name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:218
+	StreamXML(qw422016, nf.dataStyleName())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:218
+	qw422016.N().S(`"/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+func (nf namedFormat) WriteXML(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+	nf.StreamXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+func (nf namedFormat) XML() string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
+	nf.WriteXML(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
 	qs422016 := string(qb422016.B)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
 	qt422016.ReleaseByteBuffer(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
 	return qs422016
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:142
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:219
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:144
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:222
 func (t Table) StreamBegin(qw422016 *qt422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:144
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:222
 	qw422016.N().S(`<table:table table:name="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:144
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:222
 	StreamXML(qw422016, t.Name)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:144
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:222
 	qw422016.N().S(`" table:style-name="ta-0" table:print="true">
 		`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
-	if t.Style != "" {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:223
+	for _, w := range t.ColumnWidths {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:223
 		qw422016.N().S(`<table:table-column table:style-name="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
-		StreamXML(qw422016, t.Style)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
-		qw422016.N().S(`" table:number-columns-repeated="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
-		qw422016.N().D(t.ColCount)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:223
+		qw422016.E().S(columnWidthStyle(w))
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:223
 		qw422016.N().S(`"/>`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:223
 	}
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:145
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:224
+	style, n := t.restColumns()
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:225
+	if n > 0 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:225
+		qw422016.N().S(`<table:table-column table:style-name="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:225
+		StreamXML(qw422016, style)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:225
+		qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
+		if n > 1 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
+			qw422016.N().S(` table:number-columns-repeated="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
+			qw422016.N().D(n)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
+			qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
+		qw422016.N().S(`/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:226
 	qw422016.N().S(`
 		`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:146
-	t.Heading.StreamXML(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:146
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:227
+	if t.RepeatHeading && len(t.Heading.Cells) != 0 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:227
+		qw422016.N().S(`<table:table-header-rows>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:227
+		t.Heading.StreamXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:227
+		qw422016.N().S(`</table:table-header-rows>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:228
+	} else {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:228
+		t.Heading.StreamXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:228
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:228
 	qw422016.N().S(`
 `)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 func (t Table) WriteBegin(qq422016 qtio422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	t.StreamBegin(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	qt422016.ReleaseWriter(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 func (t Table) Begin() string {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	qb422016 := qt422016.AcquireByteBuffer()
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	t.WriteBegin(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	qs422016 := string(qb422016.B)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	qt422016.ReleaseByteBuffer(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 	return qs422016
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:147
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:229
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:149
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:231
 func (row Row) StreamXML(qw422016 *qt422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:150
-	if len(row.Cells) != 0 {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:150
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:232
+	if len(row.Cells) != 0 || row.Repeat > 0 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:232
 		qw422016.N().S(`<table:table-row table:style-name="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:150
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:232
 		StreamXML(qw422016, row.Style)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:150
-		qw422016.N().S(`">`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:151
-		for _, cell := range row.Cells {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:151
-			cell.StreamXML(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:152
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:232
+		qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:233
+		if row.Repeat > 1 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:233
+			qw422016.N().S(` table:number-rows-repeated="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:233
+			qw422016.N().D(row.Repeat)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:233
+			qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:233
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:233
+		qw422016.N().S(`>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:234
+		if len(row.Cells) == 0 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:234
+			qw422016.N().S(`<table:table-cell/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:234
+		}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:235
+		var pos int
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:236
+		for i := 0; i < len(row.Cells); i++ {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:237
+			cell := row.Cells[i]
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:238
+			gap := cell.ColIndex - 1 - pos
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:239
+			if cell.ColIndex > 0 && gap > 0 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:239
+				qw422016.N().S(`<table:table-cell table:number-columns-repeated="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:239
+				qw422016.N().D(gap)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:239
+				qw422016.N().S(`"/>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:240
+				pos += gap
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:241
+			}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:242
+			n := row.sameRun(i, pos)
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:242
+			cell.streamrepeatedXML(qw422016, n)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:243
+			pos += n
+			i += n - 1
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:244
 		}
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:152
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:244
 		qw422016.N().S(`</table:table-row>`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:153
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:245
 	}
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:153
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:245
 	qw422016.N().S(`
 `)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 func (row Row) WriteXML(qq422016 qtio422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	row.StreamXML(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	qt422016.ReleaseWriter(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 func (row Row) XML() string {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	qb422016 := qt422016.AcquireByteBuffer()
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	row.WriteXML(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	qs422016 := string(qb422016.B)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	qt422016.ReleaseByteBuffer(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 	return qs422016
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:154
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:246
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:156
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
 func (cell Cell) StreamXML(qw422016 *qt422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:156
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	cell.streamrepeatedXML(qw422016, 1)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+func (cell Cell) WriteXML(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	cell.StreamXML(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+func (cell Cell) XML() string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	cell.WriteXML(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:248
+}
+
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
+func (cell Cell) streamrepeatedXML(qw422016 *qt422016.Writer, n int) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
+	if cell.Raw != "" {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
+		qw422016.N().S(cell.Raw)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
+		return
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
 	qw422016.N().S(`<table:table-cell table:style-name="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:156
-	StreamXML(qw422016, cell.Style)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:156
-	qw422016.N().S(`" office:value-type="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:156
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
+	StreamXML(qw422016, cell.styleName())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:250
+	qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
+	if n > 1 {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
+		qw422016.N().S(` table:number-columns-repeated="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
+		qw422016.N().D(n)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
+		qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
+	qw422016.N().S(` office:value-type="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
 	qw422016.N().S(cell.Type.String())
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:156
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:251
 	qw422016.N().S(`"`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:157
-	if cell.Type == FloatType {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:157
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:252
+	if cell.Type == FloatType || cell.Type == IntType {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:252
 		qw422016.N().S(` office:value="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:157
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:252
 		StreamXML(qw422016, cell.Value)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:157
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:252
 		qw422016.N().S(`"`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:158
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:253
+	} else if cell.Type == BoolType {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:253
+		qw422016.N().S(` office:boolean-value="`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:253
+		StreamXML(qw422016, cell.Value)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:253
+		qw422016.N().S(`"`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:254
 	} else if cell.Type == DateType {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:158
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:254
 		qw422016.N().S(` office:date-value="`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:158
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:254
 		StreamXML(qw422016, cell.Value)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:158
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:254
 		qw422016.N().S(`"`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	}
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
-	qw422016.N().S(`><text:p>`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
-	StreamXML(qw422016, cell.Value)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+	qw422016.N().S(`>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+	if cell.picture != nil {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+		cell.picture.StreamFrame(qw422016)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+	}
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+	qw422016.N().S(`<text:p>`)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+	StreamXML(qw422016, cell.text())
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	qw422016.N().S(`</text:p></table:table-cell>`)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
-func (cell Cell) WriteXML(qq422016 qtio422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+func (cell Cell) writerepeatedXML(qq422016 qtio422016.Writer, n int) {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
-	cell.StreamXML(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+	cell.streamrepeatedXML(qw422016, n)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	qt422016.ReleaseWriter(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
-func (cell Cell) XML() string {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+func (cell Cell) repeatedXML(n int) string {
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	qb422016 := qt422016.AcquireByteBuffer()
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
-	cell.WriteXML(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
+	cell.writerepeatedXML(qb422016, n)
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	qs422016 := string(qb422016.B)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	qt422016.ReleaseByteBuffer(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 	return qs422016
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:159
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:255
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:161
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:257
 func StreamEndTable(qw422016 *qt422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:161
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:257
 	qw422016.N().S(`
       </table:table>
 `)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 func WriteEndTable(qq422016 qtio422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	StreamEndTable(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	qt422016.ReleaseWriter(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 func EndTable() string {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	qb422016 := qt422016.AcquireByteBuffer()
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	WriteEndTable(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	qs422016 := string(qb422016.B)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	qt422016.ReleaseByteBuffer(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 	return qs422016
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:163
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:259
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:165
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:261
 func StreamEndSheets(qw422016 *qt422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:165
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:261
 	qw422016.N().S(`
     </office:spreadsheet>
   </office:body>
 </office:document-content>
 `)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 func WriteEndSheets(qq422016 qtio422016.Writer) {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	StreamEndSheets(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	qt422016.ReleaseWriter(qw422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 }
 
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 func EndSheets() string {
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	qb422016 := qt422016.AcquireByteBuffer()
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	WriteEndSheets(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	qs422016 := string(qb422016.B)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	qt422016.ReleaseByteBuffer(qb422016)
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 	return qs422016
-//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:169
+//line src/github.com/tgulacsi/go/ods/content.xml.qtpl:265
 }
@@ -0,0 +1,63 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import "io"
+
+// Document is a whole spreadsheet, for StreamFlat.
+type Document struct {
+	// Options of the document; the zero value is the same as of NewWriter.
+	Options WriterOptions
+	// Sheets of the document.
+	Sheets []Sheet
+}
+
+// Sheet is a Table with its rows.
+type Sheet struct {
+	Rows  []Row
+	Table Table
+}
+
+// StreamFlat writes the document as flat ODF (.fods): one XML file,
+// with the styles and the content in an office:document root.
+//
+// The output is deterministic (no dates), so it is handy for diffing.
+// The images (see ODSWriter.AddImage) are not supported.
+func StreamFlat(w io.Writer, doc Document) error {
+	ew := &errWriter{w: w}
+	W := AcquireWriter(ew)
+	defer ReleaseWriter(W)
+	opts := doc.Options
+	styles := opts.styles()
+	for _, s := range doc.Sheets {
+		styles.UseTables(s.Table)
+		styles.UseRows(s.Table.Heading)
+		styles.UseRows(s.Rows...)
+	}
+	opts.Styles = &styles
+	StreamBeginFlat(W, opts)
+	for _, s := range doc.Sheets {
+		s.Table.StreamBegin(W)
+		for _, row := range s.Rows {
+			row.StreamXML(W)
+		}
+		StreamEndTable(W)
+	}
+	StreamEndFlat(W)
+	return ew.err
+}
+
+// errWriter keeps the first error of w, and doesn't write after it.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
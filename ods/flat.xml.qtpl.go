@@ -0,0 +1,124 @@
+// Code generated by qtc from "flat.xml.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:4
+package ods
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:4
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:4
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:4
+func StreamBeginFlat(qw422016 *qt422016.Writer, opts WriterOptions) {
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:4
+	qw422016.N().S(`<?xml version="1.0" encoding="UTF-8"?>
+
+<office:document xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:xlink="http://www.w3.org/1999/xlink" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0" xmlns:chart="urn:oasis:names:tc:opendocument:xmlns:chart:1.0" xmlns:dr3d="urn:oasis:names:tc:opendocument:xmlns:dr3d:1.0" xmlns:config="urn:oasis:names:tc:opendocument:xmlns:config:1.0" xmlns:math="http://www.w3.org/1998/Math/MathML" xmlns:form="urn:oasis:names:tc:opendocument:xmlns:form:1.0" xmlns:script="urn:oasis:names:tc:opendocument:xmlns:script:1.0" xmlns:ooo="http://openoffice.org/2004/office" xmlns:ooow="http://openoffice.org/2004/writer" xmlns:oooc="http://openoffice.org/2004/calc" xmlns:tableooo="http://openoffice.org/2009/table" xmlns:of="urn:oasis:names:tc:opendocument:xmlns:of:1.2" xmlns:dom="http://www.w3.org/2001/xml-events" xmlns:xforms="http://www.w3.org/2002/xforms" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:gnm="http://www.gnumeric.org/odf-extension/1.0" xmlns:css3t="http://www.w3.org/TR/css3-text/" xmlns:loext="urn:org:documentfoundation:names:experimental:office:xmlns:loext:1.0" xmlns:calcext="urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0" office:version="1.2" office:mimetype="application/vnd.oasis.opendocument.spreadsheet">
+  <office:meta>
+    <meta:generator>github.com/tgulacsi/go/ods</meta:generator>
+  </office:meta>
+  <office:font-face-decls/>
+  `)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:11
+	StreamCommonStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:11
+	qw422016.N().S(`
+  <office:automatic-styles>
+    `)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:13
+	StreamPageLayoutStyle(qw422016, opts.PageLayout)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:13
+	qw422016.N().S(`
+    `)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:14
+	StreamContentAutomaticStyles(qw422016, opts.styles())
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:14
+	qw422016.N().S(`
+  </office:automatic-styles>
+  `)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:16
+	StreamMasterStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:16
+	qw422016.N().S(`
+  <office:body>
+    <office:spreadsheet>
+      `)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:19
+	StreamCalculationSettings(qw422016, opts.CalcSettings)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:19
+	qw422016.N().S(`
+`)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+}
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+func WriteBeginFlat(qq422016 qtio422016.Writer, opts WriterOptions) {
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	StreamBeginFlat(qw422016, opts)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+}
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+func BeginFlat(opts WriterOptions) string {
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	WriteBeginFlat(qb422016, opts)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:20
+}
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:22
+func StreamEndFlat(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:22
+	qw422016.N().S(`
+    </office:spreadsheet>
+  </office:body>
+</office:document>
+`)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+}
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+func WriteEndFlat(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	StreamEndFlat(qw422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+}
+
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+func EndFlat() string {
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	WriteEndFlat(qb422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/flat.xml.qtpl:26
+}
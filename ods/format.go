@@ -0,0 +1,111 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NumberFormat is the definition of a number style.
+//
+// Register it with RegisterFormat, and use its name as Cell.Style.
+type NumberFormat struct {
+	// Decimals is the number of the (fixed) decimal places.
+	Decimals int
+	// MinIntegerDigits is the minimal number of integer digits, 1 if 0.
+	MinIntegerDigits int
+	// Grouping shows the thousands separator.
+	Grouping bool
+	// NegativeRed shows the negative numbers in red.
+	NegativeRed bool
+	// NegativeParens shows the negative numbers in parentheses,
+	// without the minus sign: "(1 234,50)".
+	NegativeParens bool
+	// Locale of the number style, such as "hu-HU": it determines the grouping and decimal
+	// separators (ODF has no attributes for them), for example
+	// "1 234 567,89" for hu-HU, "1,234,567.89" for en-US, "1.234.567,89" for de-DE.
+	// The default locale of the document (thus of the application) is used, if empty.
+	Locale string
+}
+
+// LocaleFormat returns a NumberFormat of the locale (such as "hu-HU", "en-US" or "de-DE"),
+// with grouping and the given number of decimals.
+//
+// The Cell values stay as they are (office:value="1234567"), Calc shows them with
+// the locale's separators ("1 234 567" for hu-HU).
+func LocaleFormat(locale string, decimals int) NumberFormat {
+	return NumberFormat{Decimals: decimals, Grouping: true, Locale: locale}
+}
+
+// localeAttrs returns the number:language and number:country attributes of the Locale.
+//
+// Invalid (non-letter) language or country parts are ignored.
+func (nf NumberFormat) localeAttrs() string {
+	language, country, _ := strings.Cut(strings.Replace(nf.Locale, "_", "-", 1), "-")
+	if !isLetters(language) {
+		return ""
+	}
+	attrs := ` number:language="` + strings.ToLower(language) + `"`
+	if isLetters(country) {
+		attrs += ` number:country="` + strings.ToUpper(country) + `"`
+	}
+	return attrs
+}
+
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || 'z' < c {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// AccountingFormat is the format registered as "accounting":
+// two decimals, grouping, negatives in red and in parentheses.
+var AccountingFormat = NumberFormat{Decimals: 2, Grouping: true, NegativeRed: true, NegativeParens: true}
+
+func (nf NumberFormat) minIntegerDigits() int {
+	if nf.MinIntegerDigits <= 0 {
+		return 1
+	}
+	return nf.MinIntegerDigits
+}
+
+type namedFormat struct {
+	Name string
+	NumberFormat
+}
+
+// dataStyleName is the name of the number style, the cell style refers to.
+func (nf namedFormat) dataStyleName() string { return "N-" + nf.Name }
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]NumberFormat{"accounting": AccountingFormat}
+)
+
+// RegisterFormat registers the number format under name: it is emitted
+// into the automatic styles as a table-cell style named name
+// (with a "N-"+name number style), so it can be used as Cell.Style.
+//
+// Register the formats before the NewWriter / StreamBeginSheets call.
+func RegisterFormat(name string, format NumberFormat) {
+	formatsMu.Lock()
+	formats[name] = format
+	formatsMu.Unlock()
+}
+
+// registeredFormats returns the registered formats, ordered by name.
+func registeredFormats() []namedFormat {
+	formatsMu.RLock()
+	nfs := make([]namedFormat, 0, len(formats))
+	for k, v := range formats {
+		nfs = append(nfs, namedFormat{Name: k, NumberFormat: v})
+	}
+	formatsMu.RUnlock()
+	sort.Slice(nfs, func(i, j int) bool { return nfs[i].Name < nfs[j].Name })
+	return nfs
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// picture is an image embedded in the document, anchored to a cell.
+type picture struct {
+	Href      string
+	MediaType string
+	sheet     string
+	data      []byte
+	Width     float64
+	Height    float64
+	col, row  int
+	index     int
+	placed    bool
+}
+
+func (p *picture) name() string { return "Image " + strconv.Itoa(p.index+1) }
+
+// AddImage adds the PNG or JPEG image to the document, anchored to the cell ref (such as "A1")
+// of the sheet, with the given size in centimeters.
+//
+// The image is placed by BeginTable, WriteRow and EndTable,
+// so AddImage must be called before BeginTable of the sheet.
+func (ow *ODSWriter) AddImage(sheet, ref string, data []byte, widthCm, heightCm float64) error {
+	col, row, err := parseCellRef(ref)
+	if err != nil {
+		return err
+	}
+	var ext string
+	mediaType := http.DetectContentType(data)
+	switch mediaType {
+	case "image/png":
+		ext = ".png"
+	case "image/jpeg":
+		ext = ".jpg"
+	default:
+		return fmt.Errorf("%s: only PNG and JPEG images are supported, got %s", ref, mediaType)
+	}
+	index := len(ow.pictures)
+	ow.pictures = append(ow.pictures, &picture{
+		Href: "Pictures/image" + strconv.Itoa(index+1) + ext, MediaType: mediaType,
+		sheet: sheet, data: data,
+		Width: widthCm, Height: heightCm,
+		col: col, row: row, index: index,
+	})
+	return nil
+}
+
+// parseCellRef parses the A1-style cell reference into 1-based column and row indexes.
+func parseCellRef(ref string) (col, row int, err error) {
+	s := strings.ToUpper(strings.ReplaceAll(ref, "$", ""))
+	var i int
+	for ; i < len(s) && 'A' <= s[i] && s[i] <= 'Z'; i++ {
+		col = col*26 + int(s[i]-'A'+1)
+	}
+	if i == 0 || i > 3 {
+		return 0, 0, fmt.Errorf("bad cell reference %q", ref)
+	}
+	if row, err = strconv.Atoi(s[i:]); err != nil || row < 1 {
+		return 0, 0, fmt.Errorf("bad cell reference %q", ref)
+	}
+	return col, row, nil
+}
+
+// BeginTable starts the table, placing the images of the sheet into the Heading.
+func (ow *ODSWriter) BeginTable(t Table) {
+	ow.table, ow.rowNum = t.Name, 0
+	if len(t.Heading.Cells) != 0 {
+		t.Heading = ow.placePictures(t.Heading)
+	}
+	t.StreamBegin(ow.qtWriter)
+}
+
+// WriteRow writes the row into the current table, placing the images anchored into it.
+//
+// A repeated row with images anchored into its range is written row by row.
+func (ow *ODSWriter) WriteRow(row Row) {
+	if len(row.Cells) == 0 && row.Repeat <= 0 {
+		return
+	}
+	if n := row.count(); n > 1 {
+		for _, p := range ow.pictures {
+			if !p.placed && p.sheet == ow.table && p.row > ow.rowNum && p.row <= ow.rowNum+n {
+				row.Repeat = 1
+				for i := 0; i < n; i++ {
+					ow.WriteRow(row)
+				}
+				return
+			}
+		}
+	}
+	ow.placePictures(row).StreamXML(ow.qtWriter)
+}
+
+// EndTable ends the current table, after writing (empty) rows for the images not placed yet.
+func (ow *ODSWriter) EndTable() {
+	var rest []*picture
+	for _, p := range ow.pictures {
+		if p.sheet == ow.table && !p.placed && p.row > ow.rowNum {
+			rest = append(rest, p)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].row < rest[j].row })
+	for _, p := range rest {
+		if p.placed {
+			continue
+		}
+		if gap := p.row - 1 - ow.rowNum; gap > 0 {
+			ow.WriteRow(Row{Repeat: gap})
+		}
+		ow.WriteRow(Row{Cells: []Cell{{ColIndex: p.col}}})
+	}
+	StreamEndTable(ow.qtWriter)
+	ow.table = ""
+}
+
+// placePictures counts the row, and returns it with the pictures anchored into it.
+func (ow *ODSWriter) placePictures(row Row) Row {
+	ow.rowNum += row.count()
+	var cells []Cell
+	for _, p := range ow.pictures {
+		if p.placed || p.sheet != ow.table || p.row != ow.rowNum {
+			continue
+		}
+		if cells == nil {
+			cells = append(make([]Cell, 0, len(row.Cells)+1), row.Cells...)
+		}
+		cells = placePicture(cells, p)
+		p.placed = true
+	}
+	if cells != nil {
+		row.Cells = cells
+	}
+	return row
+}
+
+// placePicture sets the picture of the cell in its column, inserting an empty cell if needed.
+func placePicture(cells []Cell, p *picture) []Cell {
+	var pos int
+	for i, c := range cells {
+		if gap := c.ColIndex - 1 - pos; c.ColIndex > 0 && gap > 0 {
+			if pos <= p.col-1 && p.col < c.ColIndex {
+				cells = append(cells[:i+1], cells[i:]...)
+				cells[i] = Cell{ColIndex: p.col, picture: p}
+				return cells
+			}
+			pos += gap
+		}
+		if pos == p.col-1 {
+			cells[i].picture = p
+			return cells
+		}
+		pos++
+	}
+	return append(cells, Cell{ColIndex: p.col, picture: p})
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+// PageSize is the size of the paper, in points (1/72 inch).
+type PageSize struct {
+	Width, Height float64
+}
+
+var (
+	// A4 paper size.
+	A4 = PageSize{Width: 595.2755905511812, Height: 841.8897637795276}
+	// Letter paper size.
+	Letter = PageSize{Width: 612, Height: 792}
+)
+
+// PageLayout is the print layout of the sheets, written into styles.xml.
+//
+// The zero value is A4, portrait, with 72pt (1 inch) margins.
+type PageLayout struct {
+	// Size of the paper, A4 if zero.
+	Size PageSize
+	// Margins in points, 72pt if zero.
+	MarginTop, MarginBottom, MarginLeft, MarginRight float64
+	// Landscape orientation.
+	Landscape bool
+}
+
+func (pl PageLayout) size() PageSize {
+	if pl.Size.Width <= 0 || pl.Size.Height <= 0 {
+		return A4
+	}
+	return pl.Size
+}
+
+func (pl PageLayout) width() float64 {
+	if s := pl.size(); pl.Landscape {
+		return s.Height
+	} else {
+		return s.Width
+	}
+}
+
+func (pl PageLayout) height() float64 {
+	if s := pl.size(); pl.Landscape {
+		return s.Width
+	} else {
+		return s.Height
+	}
+}
+
+func (pl PageLayout) orientation() string {
+	if pl.Landscape {
+		return "landscape"
+	}
+	return "portrait"
+}
+
+func (pl PageLayout) margin(m float64) float64 {
+	if m <= 0 {
+		return 72
+	}
+	return m
+}
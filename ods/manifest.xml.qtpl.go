@@ -0,0 +1,130 @@
+// Code generated by qtc from "manifest.xml.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:1
+package ods
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:1
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:1
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:1
+func StreamManifest(qw422016 *qt422016.Writer, pictures []*picture) {
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:1
+	qw422016.N().S(`<?xml version="1.0" encoding="UTF-8"?>
+
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+  <manifest:file-entry manifest:media-type="application/vnd.oasis.opendocument.spreadsheet" manifest:full-path="/"/>
+  <manifest:file-entry manifest:media-type="text/xml" manifest:full-path="content.xml"/>
+  <manifest:file-entry manifest:media-type="text/xml" manifest:full-path="styles.xml"/>
+  <manifest:file-entry manifest:media-type="text/xml" manifest:full-path="meta.xml"/>
+  <manifest:file-entry manifest:media-type="text/xml" manifest:full-path="settings.xml"/>
+`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:9
+	for _, p := range pictures {
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:9
+		qw422016.N().S(`  <manifest:file-entry manifest:media-type="`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:9
+		StreamXML(qw422016, p.MediaType)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:9
+		qw422016.N().S(`" manifest:full-path="`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:9
+		StreamXML(qw422016, p.Href)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:9
+		qw422016.N().S(`"/>
+`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:10
+	}
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:10
+	qw422016.N().S(`</manifest:manifest>
+`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+}
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+func WriteManifest(qq422016 qtio422016.Writer, pictures []*picture) {
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	StreamManifest(qw422016, pictures)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+}
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+func Manifest(pictures []*picture) string {
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	WriteManifest(qb422016, pictures)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:11
+}
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:14
+func (p *picture) StreamFrame(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:14
+	qw422016.N().S(`<draw:frame draw:z-index="`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	qw422016.N().D(p.index)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	qw422016.N().S(`" draw:name="`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	StreamXML(qw422016, p.name())
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	qw422016.N().S(`" svg:width="`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	qw422016.N().F(p.Width)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	qw422016.N().S(`cm" svg:height="`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	qw422016.N().F(p.Height)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:15
+	qw422016.N().S(`cm" svg:x="0cm" svg:y="0cm"><draw:image xlink:href="`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:16
+	StreamXML(qw422016, p.Href)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:16
+	qw422016.N().S(`" xlink:type="simple" xlink:show="embed" xlink:actuate="onLoad"><text:p/></draw:image></draw:frame>`)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+}
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+func (p *picture) WriteFrame(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	p.StreamFrame(qw422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+}
+
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+func (p *picture) Frame() string {
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	p.WriteFrame(qb422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/manifest.xml.qtpl:18
+}
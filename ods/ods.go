@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"strings"
 	"sync"
 
 	qt "github.com/valyala/quicktemplate"
@@ -33,24 +34,113 @@ func AcquireWriter(w io.Writer) *qt.Writer {
 func ReleaseWriter(W *qt.Writer) { qtMu.Lock(); qt.ReleaseWriter(W); qtMu.Unlock() }
 
 // Table or sheet.
+//
+// All the names and values (of the Table, Row and Cell) are raw, unescaped text:
+// they are XML-escaped exactly once, when written, so never pass pre-escaped strings.
+// The only exception is Cell.Raw, which is written verbatim.
 type Table struct {
-	Name     string
-	Style    string
-	Heading  Row
+	// Name of the sheet, see SanitizeSheetName.
+	Name string
+	// Style is the table-column style of the columns (ACOL-0 if empty).
+	Style   string
+	Heading Row
+	// ColCount is the number of the declared columns (at least one is declared).
 	ColCount int
+	// ColumnWidths are the widths of the columns, in characters (see AutoSizeColumns).
+	// If set, Style is used only for the columns after them.
+	// Their styles must be declared with AutomaticStyles.UseTables (StreamFlat does it).
+	ColumnWidths []int
+	// RepeatHeading repeats the Heading on each printed page.
+	RepeatHeading bool
 }
 
 // Row with style.
 type Row struct {
 	Style string
 	Cells []Cell
+	// Repeat the row this many times (as one table-row, with number-rows-repeated), if > 1.
+	// A Row without Cells is written as blank row(s) only if Repeat > 0.
+	Repeat int
 }
 
+// count returns the number of rows the Row is written as.
+func (row Row) count() int {
+	if row.Repeat > 1 {
+		return row.Repeat
+	}
+	return 1
+}
+
+// sameRun returns the number of consecutive cells from the i-th one (at the 0-based column pos),
+// which are the same as it, thus can be written as one table-cell with number-columns-repeated.
+//
+// Cells with pictures are never collapsed.
+func (row Row) sameRun(i, pos int) int {
+	c := row.Cells[i]
+	if c.picture != nil || c.Raw != "" {
+		return 1
+	}
+	n := 1
+	for _, d := range row.Cells[i+1:] {
+		if d.picture != nil || d.Raw != "" || d.Style != c.Style || d.Align != c.Align || d.Wrap != c.Wrap || d.Value != c.Value || d.Text != c.Text || d.Type != c.Type ||
+			(d.ColIndex > 0 && d.ColIndex != pos+n+1) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// NewRow returns a Row of string cells with the given values, each with the given cell style.
+//
+// Empty values are kept as empty cells, to preserve the column alignment.
+func NewRow(style string, values ...string) Row {
+	row := Row{Cells: make([]Cell, len(values))}
+	for i, v := range values {
+		row.Cells[i] = Cell{Style: style, Value: v, Type: StringType}
+	}
+	return row
+}
+
+// NewTextRow returns a Row of unstyled string cells.
+func NewTextRow(values ...string) Row { return NewRow("", values...) }
+
 // Cell with style, type and value.
 type Cell struct {
 	Style string
+	// Value is the raw (unescaped) value.
 	Value string
-	Type  ValueType
+	// Text is the displayed (raw) text, if different from the Value, such as a formatted date.
+	Text string
+	// ColIndex is the 1-based column index of the cell.
+	// If set, the columns before it are filled with empty cells;
+	// 0 means the next column.
+	ColIndex int
+	Type     ValueType
+	// Align is the horizontal alignment of the text.
+	Align Alignment
+	// Wrap the text into multiple lines, to fit the column width.
+	//
+	// A Cell with Align or Wrap and an empty Style or a registered format (see RegisterFormat)
+	// gets a generated style, which must be declared with AutomaticStyles.UseRows
+	// (StreamFlat does it). With any other Style, Align and Wrap are ignored:
+	// put the alignment and wrapping into that style.
+	Wrap bool
+	// Raw is a pre-rendered table-cell element, written as is, instead of the cell:
+	// it is NOT escaped nor checked, and all the other fields (except ColIndex) are ignored.
+	// It must be exactly one well-formed table:table-cell (or table:covered-table-cell) element.
+	Raw string
+
+	// picture anchored to this cell, see ODSWriter.AddImage
+	picture *picture
+}
+
+// text returns the displayed text of the cell.
+func (c Cell) text() string {
+	if c.Text != "" {
+		return c.Text
+	}
+	return c.Value
 }
 
 // ValueType is the cell's value's type.
@@ -62,6 +152,10 @@ func (v ValueType) String() string {
 		return "float"
 	case 'd':
 		return "date"
+	case 'b':
+		return "boolean"
+	case 'i':
+		return "float"
 	default:
 		return "string"
 	}
@@ -74,15 +168,110 @@ const (
 	DateType = ValueType('d')
 	// StringType for everything else
 	StringType = ValueType('s')
+	// BoolType for booleans ("true" or "false")
+	BoolType = ValueType('b')
+	// IntType for integers, stored as float
+	IntType = ValueType('i')
 )
 
+// CalcSettings are the calculation settings of the document.
+type CalcSettings struct {
+	// NullDate is the date of the 0 value, "1899-12-30" if empty.
+	// Use "1904-01-01" for the Excel 1904 date system.
+	NullDate string
+	// NullYear is the start of the century two-digit years are in, 1930 if 0.
+	NullYear int
+	// CaseSensitive comparisons.
+	CaseSensitive bool
+	// UseRegularExpressions in formulas.
+	UseRegularExpressions bool
+	// UseWildcards in formulas.
+	UseWildcards bool
+}
+
+// DefaultCalcSettings are used by StreamBeginSheets.
+var DefaultCalcSettings = CalcSettings{NullDate: "1899-12-30", NullYear: 1930}
+
+func (cs CalcSettings) nullDate() string {
+	if cs.NullDate == "" {
+		return "1899-12-30"
+	}
+	return cs.NullDate
+}
+
+func (cs CalcSettings) nullYear() int {
+	if cs.NullYear == 0 {
+		return 1930
+	}
+	return cs.NullYear
+}
+
 // NewWriter returns a content writer and a zip closer for an ods file.
 func NewWriter(w io.Writer) (*ODSWriter, error) {
+	return NewWriterWith(w, DefaultCalcSettings)
+}
+
+// NewWriterWith is like NewWriter, but with the given calculation settings.
+func NewWriterWith(w io.Writer, settings CalcSettings) (*ODSWriter, error) {
+	return NewWriterOptions(w, WriterOptions{CalcSettings: settings})
+}
+
+// WriterOptions for NewWriterOptions.
+type WriterOptions struct {
+	// PageLayout is written into styles.xml.
+	PageLayout PageLayout
+	// CalcSettings are written into content.xml.
+	CalcSettings CalcSettings
+	// Styles are the automatic styles of content.xml;
+	// the default ones (AutomaticStyles{Defaults: true}) if nil.
+	// Declare the column widths of the tables with AutomaticStyles.UseTables,
+	// and the alignments of the cells with AutomaticStyles.UseRows.
+	Styles *AutomaticStyles
+}
+
+func (opts WriterOptions) styles() AutomaticStyles {
+	if opts.Styles == nil {
+		return AutomaticStyles{Defaults: true}
+	}
+	return *opts.Styles
+}
+
+// AutomaticStyles are the office:automatic-styles of content.xml,
+// see StreamBeginSheetsWithStyles.
+type AutomaticStyles struct {
+	// Custom is the raw XML of the style elements (such as <style:style>),
+	// written as is, thus it must be well-formed.
+	Custom string
+	// Defaults includes the default styles (AC-*, ACE-*, ACOL-*, AROW-*),
+	// before the Custom ones.
+	Defaults bool
+
+	// columnWidths are the (rounded, sorted) column widths in use, see UseTables.
+	columnWidths []int
+	// alignStyles are the alignment styles in use, see UseRows.
+	alignStyles []alignStyle
+}
+
+// NewWriterOptions is like NewWriter, but with the given options.
+func NewWriterOptions(w io.Writer, opts WriterOptions) (*ODSWriter, error) {
 	zw := zip.NewWriter(w)
-	if err := fs.WalkDir(statikFS, "/", func(path string, info fs.DirEntry, err error) error {
+	// mimetype must be the first, uncompressed
+	mt, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err == nil {
+		_, err = io.WriteString(mt, mimeType)
+	}
+	if err != nil {
+		zw.Close()
+		return nil, err
+	}
+	if err := fs.WalkDir(statikFS, "assets", func(path string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		name := strings.TrimPrefix(path, "assets/")
+		if info.IsDir() || name == "mimetype" {
+			return nil
+		}
 		b, err := fs.ReadFile(statikFS, path)
 		if err != nil {
 			return fmt.Errorf("%s %s: %w", path, info, err)
@@ -95,6 +284,7 @@ func NewWriter(w io.Writer) (*ODSWriter, error) {
 		if err != nil {
 			return fmt.Errorf("%s: %w", path, err)
 		}
+		hdr.Name = name
 		hdr.Method = zip.Deflate
 		w, err := zw.CreateHeader(hdr)
 		if err != nil {
@@ -107,21 +297,36 @@ func NewWriter(w io.Writer) (*ODSWriter, error) {
 		return nil, fmt.Errorf("walk: %w", err)
 	}
 
+	sw, err := zw.Create("styles.xml")
+	if err != nil {
+		zw.Close()
+		return nil, err
+	}
+	W := AcquireWriter(sw)
+	StreamStyles(W, opts.PageLayout)
+	ReleaseWriter(W)
+
 	bw, err := zw.Create("content.xml")
 	if err != nil {
 		zw.Close()
 		return nil, err
 	}
-	W := AcquireWriter(bw)
-	StreamBeginSheets(W)
+	W = AcquireWriter(bw)
+	StreamBeginSheetsWithStyles(W, opts.CalcSettings, opts.styles())
 
 	return &ODSWriter{qtWriter: W, zipWriter: zw}, nil
 }
 
+const mimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
 // ODSWriter writes content.xml of ODS zip.
 type ODSWriter struct {
 	qtWriter  *qt.Writer
 	zipWriter *zip.Writer
+	pictures  []*picture
+	// table is the name of the current table, rowNum is its last written row
+	table  string
+	rowNum int
 }
 
 func (ow *ODSWriter) QTWriter() *qt.Writer { return ow.qtWriter }
@@ -133,9 +338,28 @@ func (ow *ODSWriter) Close() error {
 	}
 	StreamEndSheets(ow.qtWriter)
 	ow.qtWriter = nil
-	err := ow.zipWriter.Close()
+	zw := ow.zipWriter
 	ow.zipWriter = nil
-	return err
+	for _, p := range ow.pictures {
+		// already compressed
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: p.Href, Method: zip.Store})
+		if err == nil {
+			_, err = w.Write(p.data)
+		}
+		if err != nil {
+			zw.Close()
+			return fmt.Errorf("%s: %w", p.Href, err)
+		}
+	}
+	w, err := zw.Create("META-INF/manifest.xml")
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	W := AcquireWriter(w)
+	StreamManifest(W, ow.pictures)
+	ReleaseWriter(W)
+	return zw.Close()
 }
 
 // Style information - generated from content.xml with github.com/miek/zek/cmd/zek.
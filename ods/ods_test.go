@@ -0,0 +1,784 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeOnce(t *testing.T) {
+	const name, value = `Q&A <2023> "final"`, `a < b && c > "d"`
+	tbl := Table{Name: name, Heading: NewTextRow(value)}
+	s := tbl.Begin() + EndTable()
+	if strings.Contains(s, "&amp;amp;") || strings.Contains(s, "&amp;lt;") {
+		t.Errorf("double escaped: %s", s)
+	}
+
+	dec := xml.NewDecoder(strings.NewReader(s))
+	dec.Strict = false
+	var gotName, gotValue string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		switch x := tok.(type) {
+		case xml.StartElement:
+			if x.Name.Local == "table" {
+				for _, a := range x.Attr {
+					if a.Name.Local == "name" {
+						gotName = a.Value
+					}
+				}
+			}
+		case xml.CharData:
+			gotValue += strings.TrimSpace(string(x))
+		}
+	}
+	if gotName != name {
+		t.Errorf("name: got %q, wanted %q", gotName, name)
+	}
+	if gotValue != value {
+		t.Errorf("value: got %q, wanted %q", gotValue, value)
+	}
+}
+
+func TestAccountingFormat(t *testing.T) {
+	s := NumberStyles()
+	for _, want := range []string{
+		`<number:number-style style:name="N-accounting">`,
+		`<style:text-properties fo:color="#ff0000"/><number:text>(</number:text>`,
+		`<number:text>)</number:text><style:map style:condition="value()&gt;=0" style:apply-style-name="N-accounting-P0"/>`,
+		`<style:style style:name="accounting" style:family="table-cell"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q is missing from\n%s", want, s)
+		}
+	}
+	if err := xml.Unmarshal([]byte("<x>"+s+"</x>"), new(struct{})); err != nil {
+		t.Error(err)
+	}
+	if got := FloatCell(-1234.5, "accounting").XML(); !strings.Contains(got, `table:style-name="accounting"`) ||
+		!strings.Contains(got, `office:value="-1234.5"`) {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestLocaleFormat(t *testing.T) {
+	for _, tc := range []struct {
+		Locale, Want string
+	}{
+		{"hu-HU", ` number:language="hu" number:country="HU"`},
+		{"en_us", ` number:language="en" number:country="US"`},
+		{"de", ` number:language="de"`},
+		{"", ""},
+		{`"><x`, ""},
+	} {
+		if got := LocaleFormat(tc.Locale, 0).localeAttrs(); got != tc.Want {
+			t.Errorf("%q: got %q, wanted %q", tc.Locale, got, tc.Want)
+		}
+	}
+	s := namedFormat{Name: "hu", NumberFormat: LocaleFormat("hu-HU", 2)}.XML()
+	if want := `<number:number-style style:name="N-hu" number:language="hu" number:country="HU"><number:number number:decimal-places="2" number:min-decimal-places="2" number:min-integer-digits="1" number:grouping="true"/></number:number-style>`; !strings.Contains(s, want) {
+		t.Errorf("%q is missing from\n%s", want, s)
+	}
+}
+
+func TestAutomaticStyles(t *testing.T) {
+	const custom = `<style:style style:name="AC-italic" style:family="text"><style:text-properties fo:font-style="oblique"/></style:style>`
+	s := BeginSheetsWithStyles(DefaultCalcSettings, AutomaticStyles{Custom: custom}) + EndSheets()
+	if strings.Count(s, `style:name="AC-italic"`) != 1 || !strings.Contains(s, custom) {
+		t.Errorf("custom style is not the only AC-italic: %s", s)
+	}
+	if strings.Contains(s, "AC-weight100") {
+		t.Error("default styles are included")
+	}
+	if !strings.Contains(s, `style:name="ta-0"`) {
+		t.Error("ta-0 is missing")
+	}
+	if err := xml.Unmarshal([]byte(s), new(struct{})); err != nil {
+		t.Error(err)
+	}
+
+	if s = BeginSheetsWith(DefaultCalcSettings); !strings.Contains(s, "AC-weight100") {
+		t.Error("default styles are missing")
+	}
+}
+
+func TestStreamFlat(t *testing.T) {
+	doc := Document{Sheets: []Sheet{
+		{Table: Table{Name: "First", Heading: NewTextRow("a", "b")},
+			Rows: []Row{NewRowAny(1, "x"), NewRowAny(2.5, true)}},
+		{Table: Table{Name: "Second"}, Rows: []Row{NewTextRow("c")}},
+	}}
+	var buf, buf2 strings.Builder
+	if err := StreamFlat(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	var root struct {
+		XMLName xml.Name
+		Body    struct {
+			Spreadsheet struct {
+				Tables []struct {
+					Name string     `xml:"name,attr"`
+					Rows []struct{} `xml:"table-row"`
+				} `xml:"table"`
+			} `xml:"spreadsheet"`
+		} `xml:"body"`
+	}
+	if err := xml.Unmarshal([]byte(s), &root); err != nil {
+		t.Fatal(err)
+	}
+	if root.XMLName.Local != "document" {
+		t.Errorf("root is %v", root.XMLName)
+	}
+	if tbls := root.Body.Spreadsheet.Tables; len(tbls) != 2 || tbls[0].Name != "First" || len(tbls[0].Rows) != 3 ||
+		tbls[1].Name != "Second" || len(tbls[1].Rows) != 1 {
+		t.Errorf("got %+v", tbls)
+	}
+	for _, want := range []string{"<office:styles>", `style:name="pl-0"`, `style:name="ta-mp-0"`, `style:name="ta-0"`, "AC-italic"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q is missing", want)
+		}
+	}
+	if err := StreamFlat(&buf2, doc); err != nil {
+		t.Fatal(err)
+	}
+	if buf2.String() != s {
+		t.Error("output is not deterministic")
+	}
+}
+
+func TestDateCell(t *testing.T) {
+	tm := time.Date(2023, 3, 31, 23, 30, 5, 0, time.FixedZone("CEST", 2*3600))
+	for _, tc := range []struct {
+		Cell        Cell
+		Value, Text string
+	}{
+		{DateCell(tm), "2023-03-31", "2023.03.31."},
+		{DateCell(tm.UTC()), "2023-03-31", "2023.03.31."},
+		{DateTimeCell(tm), "2023-03-31T23:30:05", "2023.03.31. 23:30:05"},
+		{DateTimeCell(tm.UTC()), "2023-03-31T21:30:05", "2023.03.31. 21:30:05"},
+		{DateCell(tm.Add(time.Hour)), "2023-04-01", "2023.04.01."},
+	} {
+		want := `office:value-type="date" office:date-value="` + tc.Value + `"><text:p>` + tc.Text + `</text:p>`
+		if got := tc.Cell.XML(); !strings.Contains(got, want) {
+			t.Errorf("got %s, wanted %s", got, want)
+		}
+	}
+}
+
+func TestRowRepeat(t *testing.T) {
+	if got, want := strings.TrimSpace((Row{Repeat: 3}).XML()), `<table:table-row table:style-name="" table:number-rows-repeated="3"><table:table-cell/></table:table-row>`; got != want {
+		t.Errorf("got %s, wanted %s", got, want)
+	}
+	if got := strings.TrimSpace((Row{}).XML()); got != "" {
+		t.Errorf("empty row: got %q", got)
+	}
+	if got := (Row{Repeat: 1, Cells: []Cell{{Value: "a"}}}).XML(); strings.Contains(got, "repeated") {
+		t.Errorf("single row: got %s", got)
+	}
+
+	// an image in the repeated range splits the row
+	var buf bytes.Buffer
+	ow, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ow.AddImage("S", "B4", []byte("\x89PNG\r\n\x1a\n"), 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	var rows strings.Builder
+	ow.qtWriter = AcquireWriter(&rows)
+	ow.BeginTable(Table{Name: "S"})
+	ow.WriteRow(NewTextRow("a"))
+	ow.WriteRow(Row{Repeat: 5})
+	ow.WriteRow(NewTextRow("b"))
+	ow.EndTable()
+	s := rows.String()
+	if n := strings.Count(s, "<table:table-row "); n != 7 {
+		t.Errorf("got %d rows: %s", n, s)
+	}
+	if !strings.Contains(s, "<draw:frame") {
+		t.Errorf("no image: %s", s)
+	}
+	if ow.rowNum != 7 {
+		t.Errorf("rowNum: got %d, wanted 7", ow.rowNum)
+	}
+}
+
+func TestRepeatedCells(t *testing.T) {
+	values := make([]string, 256)
+	values[0], values[1] = "a", "b"
+	tbl := Table{Name: "S", Heading: NewRow("hdr", values...)}
+	got := tbl.Begin()
+	if n := strings.Count(got, "<table:table-cell "); n != 3 {
+		t.Errorf("got %d cells, wanted 3: %s", n, got)
+	}
+	if !strings.Contains(got, `<table:table-cell table:style-name="hdr" table:number-columns-repeated="254" office:value-type="string">`) {
+		t.Errorf("no repeated cell: %s", got)
+	}
+	if len(got) > 1024 {
+		t.Errorf("too long (%d): %s", len(got), got)
+	}
+
+	// ColIndex gaps are kept, and break the run
+	row := Row{Cells: []Cell{{Value: "x"}, {Value: "x"}, {Value: "x", ColIndex: 4}, {Value: "x"}}}
+	got = strings.TrimSpace(row.XML())
+	want := `<table:table-row table:style-name=""><table:table-cell table:style-name="" table:number-columns-repeated="2" office:value-type="string"><text:p>x</text:p></table:table-cell>` +
+		`<table:table-cell table:number-columns-repeated="1"/>` +
+		`<table:table-cell table:style-name="" table:number-columns-repeated="2" office:value-type="string"><text:p>x</text:p></table:table-cell></table:table-row>`
+	if got != want {
+		t.Errorf("got\n%s\nwanted\n%s", got, want)
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	for _, tc := range []struct {
+		tbl  Table
+		want string
+		n    int
+	}{
+		{Table{}, `<table:table-column table:style-name="ACOL-0"/>`, 1},
+		{Table{ColCount: 30}, `<table:table-column table:style-name="ACOL-0" table:number-columns-repeated="30"/>`, 1},
+		{Table{ColCount: 3, Style: "co1"}, `<table:table-column table:style-name="co1" table:number-columns-repeated="3"/>`, 1},
+		{Table{ColCount: 2, ColumnWidths: []int{4, 10}}, `<table:table-column table:style-name="ACOL-W4"/><table:table-column table:style-name="ACOL-W10"/>`, 2},
+		{Table{ColCount: 5, ColumnWidths: []int{4, 10}}, `<table:table-column table:style-name="ACOL-W10"/><table:table-column table:style-name="ACOL-0" table:number-columns-repeated="3"/>`, 3},
+	} {
+		tc.tbl.Name, tc.tbl.Heading = "S", NewTextRow("a")
+		got := tc.tbl.Begin()
+		if !strings.Contains(got, tc.want) {
+			t.Errorf("%+v: got\n%s\nwanted\n%s", tc.tbl, got, tc.want)
+		}
+		if n := strings.Count(got, "<table:table-column "); n != tc.n {
+			t.Errorf("%+v: got %d columns, wanted %d", tc.tbl, n, tc.n)
+		}
+	}
+
+	// only the used widths are declared
+	if s := BeginSheets(); strings.Contains(s, "ACOL-W") {
+		t.Errorf("undeclared width styles: %s", s)
+	}
+	var as AutomaticStyles
+	as.UseTables(Table{ColumnWidths: []int{10, 3, 4}}, Table{ColumnWidths: []int{9, 1000}})
+	if !reflect.DeepEqual(as.columnWidths, []int{4, 10, MaxColumnChars}) {
+		t.Errorf("got %v", as.columnWidths)
+	}
+	var buf strings.Builder
+	if err := StreamFlat(&buf, Document{Sheets: []Sheet{{Table: Table{Name: "S", ColumnWidths: []int{4, 9}}}}}); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	if n := strings.Count(s, `style:family="table-column"><style:table-column-properties style:column-width=`); n != 2 ||
+		!strings.Contains(s, `style:name="ACOL-W4"`) || !strings.Contains(s, `style:name="ACOL-W10"`) {
+		t.Errorf("got %d width styles: %s", n, s)
+	}
+}
+
+func TestAlignWrap(t *testing.T) {
+	// a wrapped, centered title in a right-aligned numeric sheet
+	title := Cell{Value: "A long description of the numbers below", Type: StringType, Align: AlignCenter, Wrap: true}
+	num := FloatCell(1234.5, "accounting")
+	num.Align = AlignRight
+	rows := []Row{{Cells: []Cell{title, title}}, {Cells: []Cell{num, num, FloatCell(1, "accounting")}}}
+
+	var buf strings.Builder
+	if err := StreamFlat(&buf, Document{Sheets: []Sheet{{Table: Table{Name: "S"}, Rows: rows}}}); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	if err := xml.Unmarshal([]byte(s), new(struct{})); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<style:style style:name="AA-center-wrap" style:family="table-cell" style:parent-style-name="Gnumeric-default"><style:table-cell-properties fo:wrap-option="wrap" style:text-align-source="fix"/><style:paragraph-properties fo:text-align="center"/></style:style>`,
+		`<style:style style:name="AA-right-accounting" style:family="table-cell" style:parent-style-name="Gnumeric-default" style:data-style-name="N-accounting"><style:table-cell-properties fo:wrap-option="no-wrap" style:text-align-source="fix"/><style:paragraph-properties fo:text-align="end"/></style:style>`,
+		`<table:table-cell table:style-name="AA-center-wrap" table:number-columns-repeated="2" office:value-type="string">`,
+		`<table:table-cell table:style-name="AA-right-accounting" table:number-columns-repeated="2" office:value-type="float" office:value="1234.5">`,
+		`<table:table-cell table:style-name="accounting" office:value-type="float" office:value="1">`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%s is missing", want)
+		}
+	}
+	if n := strings.Count(s, `style:name="AA-center-wrap"`); n != 1 {
+		t.Errorf("AA-center-wrap is defined %d times", n)
+	}
+	// only the used combinations are declared
+	if n := strings.Count(s, `style:name="AA-`); n != 2 {
+		t.Errorf("got %d alignment styles", n)
+	}
+	if s = BeginSheets(); strings.Contains(s, `style:name="AA-`) {
+		t.Errorf("undeclared alignment styles: %s", s)
+	}
+
+	// non-format styles are kept, Align and Wrap are ignored
+	bold := Cell{Style: "ACE-0", Value: "x", Type: StringType, Wrap: true, Align: AlignCenter}
+	if got := bold.styleName(); got != "ACE-0" {
+		t.Errorf("got %q, wanted ACE-0", got)
+	}
+	var as AutomaticStyles
+	as.UseRows(Row{Cells: []Cell{bold, title, title, num}})
+	if len(as.alignStyles) != 2 {
+		t.Errorf("got %+v", as.alignStyles)
+	}
+	if got := (Cell{Style: "ACE-1"}).styleName(); got != "ACE-1" {
+		t.Errorf("got %q, wanted ACE-1", got)
+	}
+}
+
+func TestRawCell(t *testing.T) {
+	const raw = `<table:table-cell table:number-columns-spanned="2" office:value-type="string"><text:p>a&amp;b</text:p></table:table-cell>`
+	row := Row{Cells: []Cell{{Raw: raw}, {Raw: raw}, {Value: "<c>", ColIndex: 4}}}
+	got := strings.TrimSpace(row.XML())
+	want := `<table:table-row table:style-name="">` + raw + raw +
+		`<table:table-cell table:number-columns-repeated="1"/>` +
+		`<table:table-cell table:style-name="" office:value-type="string"><text:p>&lt;c&gt;</text:p></table:table-cell></table:table-row>`
+	if got != want {
+		t.Errorf("got\n%s\nwanted\n%s", got, want)
+	}
+}
+
+func TestPivot(t *testing.T) {
+	body, heading := Pivot([][]string{
+		{"x", "alice", "age", "30"},
+		{"x", "bob", "city", "Budapest"},
+		{"x", "alice", "city", "Szeged"},
+		{"short"},
+		{"x", "bob", "age", "40"},
+		{"x", "carol", "email", "c@example.com"},
+		{"x", "bob", "age", "41"},
+	}, 1, 2, 3)
+	texts := func(row Row) string {
+		ss := make([]string, len(row.Cells))
+		for i, c := range row.Cells {
+			ss[i] = c.Value
+		}
+		return strings.Join(ss, ",")
+	}
+	if got, want := texts(heading), ",age,city,email"; got != want {
+		t.Errorf("heading: got %q, wanted %q", got, want)
+	}
+	want := []string{"alice,30,Szeged,", "bob,41,Budapest,", "carol,,,c@example.com"}
+	if len(body) != len(want) {
+		t.Fatalf("got %d rows, wanted %d", len(body), len(want))
+	}
+	for i, row := range body {
+		if got := texts(row); got != want[i] {
+			t.Errorf("%d. got %q, wanted %q", i, got, want[i])
+		}
+	}
+
+	for _, cols := range [][3]int{{-1, 2, 3}, {1, -2, 3}, {1, 2, -3}} {
+		body, heading := Pivot([][]string{{"x", "alice", "age", "30"}}, cols[0], cols[1], cols[2])
+		if len(body) != 0 || texts(heading) != "" {
+			t.Errorf("%v: got %d rows, heading %q, wanted nothing", cols, len(body), texts(heading))
+		}
+	}
+}
+
+func TestParseCellRef(t *testing.T) {
+	for _, tc := range []struct {
+		ref      string
+		col, row int
+		ok       bool
+	}{
+		{"A1", 1, 1, true},
+		{"$A$1", 1, 1, true},
+		{"b4", 2, 4, true},
+		{"Z9", 26, 9, true},
+		{"AA10", 27, 10, true},
+		{"AAA1", 703, 1, true},
+		{"AAAA1", 0, 0, false},
+		{"A0", 0, 0, false},
+		{"A-1", 0, 0, false},
+		{"1A", 0, 0, false},
+		{"A", 0, 0, false},
+		{"", 0, 0, false},
+	} {
+		col, row, err := parseCellRef(tc.ref)
+		if (err == nil) != tc.ok {
+			t.Errorf("%q: got error %v, wanted ok=%t", tc.ref, err, tc.ok)
+		} else if col != tc.col || row != tc.row {
+			t.Errorf("%q: got %d,%d, wanted %d,%d", tc.ref, col, row, tc.col, tc.row)
+		}
+	}
+}
+
+func TestPlacePicture(t *testing.T) {
+	// columns returns the 1-based column of each cell.
+	columns := func(cells []Cell) []int {
+		cols := make([]int, len(cells))
+		var col int
+		for i, c := range cells {
+			if c.ColIndex > col {
+				col = c.ColIndex
+			} else {
+				col++
+			}
+			cols[i] = col
+		}
+		return cols
+	}
+	// A, B, then a gap till E
+	gapped := func() []Cell {
+		return []Cell{{Value: "a"}, {Value: "b"}, {Value: "e", ColIndex: 5}, {Value: "f"}}
+	}
+	for _, tc := range []struct {
+		name   string
+		cells  []Cell
+		col    int
+		values []string
+	}{
+		{"first", gapped(), 1, []string{"a", "b", "e", "f"}},
+		{"before gap", gapped(), 2, []string{"a", "b", "e", "f"}},
+		{"gap start", gapped(), 3, []string{"a", "b", "", "e", "f"}},
+		{"inside gap", gapped(), 4, []string{"a", "b", "", "e", "f"}},
+		{"after gap", gapped(), 5, []string{"a", "b", "e", "f"}},
+		{"last", gapped(), 6, []string{"a", "b", "e", "f"}},
+		{"after last", gapped(), 7, []string{"a", "b", "e", "f", ""}},
+		{"far after last", gapped(), 20, []string{"a", "b", "e", "f", ""}},
+		{"empty", nil, 3, []string{""}},
+		{"leading gap", []Cell{{Value: "c", ColIndex: 3}}, 1, []string{"", "c"}},
+	} {
+		p := &picture{col: tc.col}
+		cells := placePicture(tc.cells, p)
+		values := make([]string, len(cells))
+		for i, c := range cells {
+			values[i] = c.Value
+		}
+		if strings.Join(values, ",") != strings.Join(tc.values, ",") {
+			t.Errorf("%s: got cells %q, wanted %q", tc.name, values, tc.values)
+		}
+		var found int
+		for i, col := range columns(cells) {
+			if cells[i].picture == nil {
+				continue
+			}
+			found++
+			if col != tc.col {
+				t.Errorf("%s: picture in column %d, wanted %d", tc.name, col, tc.col)
+			}
+		}
+		if found != 1 {
+			t.Errorf("%s: got %d pictures", tc.name, found)
+		}
+		// the columns of the original cells are kept
+		want := columns(gapped())
+		if tc.name != "empty" && tc.name != "leading gap" {
+			var i int
+			for j, col := range columns(cells) {
+				if cells[j].Value == "" {
+					continue
+				}
+				if col != want[i] {
+					t.Errorf("%s: %q moved from column %d to %d", tc.name, cells[j].Value, want[i], col)
+				}
+				i++
+			}
+		}
+	}
+}
+
+func TestPlacePictures(t *testing.T) {
+	// frameRows returns the 1-based row numbers of the draw:frames.
+	frameRows := func(s string) []int {
+		var rows []int
+		var n int
+		for _, part := range strings.Split(s, "<table:table-row ")[1:] {
+			n++
+			if strings.Contains(part, "<draw:frame") {
+				rows = append(rows, n)
+			}
+			if i := strings.Index(part, `table:number-rows-repeated="`); i >= 0 && i < strings.IndexByte(part, '>') {
+				var k int
+				for _, c := range part[i+len(`table:number-rows-repeated="`):] {
+					if c < '0' || '9' < c {
+						break
+					}
+					k = k*10 + int(c-'0')
+				}
+				n += k - 1
+			}
+		}
+		return rows
+	}
+	for _, tc := range []struct {
+		name    string
+		ref     string
+		heading bool
+		rows    []Row
+		rowNum  int
+	}{
+		{"heading", "C1", true, []Row{NewTextRow("a")}, 2},
+		{"first data row", "A2", true, []Row{NewTextRow("a"), NewTextRow("b")}, 3},
+		{"repeat start", "B2", false, []Row{NewTextRow("a"), {Repeat: 5}}, 6},
+		{"repeat spanning", "B4", false, []Row{NewTextRow("a"), {Repeat: 5}, NewTextRow("b")}, 7},
+		{"repeat end", "B6", false, []Row{NewTextRow("a"), {Repeat: 5}}, 6},
+		{"below last", "C10", false, []Row{NewTextRow("a"), NewTextRow("b")}, 10},
+		{"right below last", "A3", false, []Row{NewTextRow("a"), NewTextRow("b")}, 3},
+	} {
+		var buf bytes.Buffer
+		ow, err := NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ow.AddImage("S", tc.ref, []byte("\x89PNG\r\n\x1a\n"), 1, 1); err != nil {
+			t.Fatal(err)
+		}
+		_, wantRow, _ := parseCellRef(tc.ref)
+		var rows strings.Builder
+		ow.qtWriter = AcquireWriter(&rows)
+		tbl := Table{Name: "S"}
+		if tc.heading {
+			tbl.Heading = NewTextRow("hdr")
+		}
+		ow.BeginTable(tbl)
+		for _, row := range tc.rows {
+			ow.WriteRow(row)
+		}
+		ow.EndTable()
+		if got := frameRows(rows.String()); len(got) != 1 || got[0] != wantRow {
+			t.Errorf("%s: got frames in rows %v, wanted %d: %s", tc.name, got, wantRow, rows.String())
+		}
+		if ow.rowNum != tc.rowNum {
+			t.Errorf("%s: rowNum: got %d, wanted %d", tc.name, ow.rowNum, tc.rowNum)
+		}
+	}
+}
+
+func TestServeODS(t *testing.T) {
+	errBuild := errors.New("build failed")
+	// writeRows writes n rows of random (not well compressible) text.
+	writeRows := func(ow *ODSWriter, n int) error {
+		ow.BeginTable(Table{Name: "S"})
+		b := make([]byte, 512)
+		for i := 0; i < n; i++ {
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			ow.WriteRow(NewTextRow(hex.EncodeToString(b)))
+		}
+		ow.EndTable()
+		return nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := ServeODS(w, "ár.ods", func(ow *ODSWriter) error { return writeRows(ow, 10) }); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("status: got %d", w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != mimeType {
+			t.Errorf("Content-Type: got %q", got)
+		}
+		if got := w.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment;") || !strings.Contains(got, "filename") {
+			t.Errorf("Content-Disposition: got %q", got)
+		}
+		b := w.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make(map[string]bool, len(zr.File))
+		for _, f := range zr.File {
+			names[f.Name] = true
+		}
+		if len(zr.File) == 0 || zr.File[0].Name != "mimetype" || !names["content.xml"] {
+			t.Errorf("got files %v", names)
+		}
+	})
+
+	t.Run("error before commit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := ServeODS(w, "x.ods", func(ow *ODSWriter) error {
+			if err := writeRows(ow, 10); err != nil {
+				return err
+			}
+			return errBuild
+		})
+		if !errors.Is(err, errBuild) {
+			t.Errorf("got error %v", err)
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("status: got %d", w.Code)
+		}
+		if got := w.Header().Get("Content-Disposition"); got != "" {
+			t.Errorf("Content-Disposition: got %q", got)
+		}
+		if got := w.Body.String(); !strings.Contains(got, errBuild.Error()) || strings.Contains(got, "mimetype") {
+			t.Errorf("body: got %q", got)
+		}
+	})
+
+	t.Run("error after commit", func(t *testing.T) {
+		build := func(ow *ODSWriter) error {
+			// more than serveHoldBack after compression
+			if err := writeRows(ow, 4*serveHoldBack/512); err != nil {
+				return err
+			}
+			return errBuild
+		}
+		errc := make(chan error, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			errc <- ServeODS(w, "x.ods", build)
+		}))
+		defer srv.Close()
+		resp, err := srv.Client().Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("status: got %d", resp.StatusCode)
+		}
+		b, err := io.ReadAll(resp.Body)
+		if err == nil {
+			t.Errorf("download succeeded with %d bytes", len(b))
+		}
+		if err = <-errc; !errors.Is(err, errBuild) {
+			t.Errorf("got error %v", err)
+		}
+
+		// without Hijacker, the handler is aborted by a panic
+		w := httptest.NewRecorder()
+		func() {
+			defer func() {
+				if r := recover(); r != http.ErrAbortHandler {
+					t.Errorf("recovered %v, wanted ErrAbortHandler", r)
+				}
+			}()
+			_ = ServeODS(w, "x.ods", build)
+		}()
+		if b := w.Body.Bytes(); len(b) <= serveHoldBack {
+			t.Errorf("body: got %d bytes", len(b))
+		} else if _, err := zip.NewReader(bytes.NewReader(b), int64(len(b))); err == nil {
+			t.Error("the truncated body is a valid zip")
+		}
+	})
+}
+
+func TestSheetNames(t *testing.T) {
+	long := strings.Repeat("ő", MaxSheetNameLength+5)
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", "Sheet"},
+		{"  ", "Sheet"},
+		{"'", "Sheet"},
+		{"'''", "Sheet"},
+		{"'a'", "a"},
+		{"a/b[c]*?:\\d", "a_b_c_____d"},
+		{"a\tb", "a_b"},
+		{long, long[:2*MaxSheetNameLength]},
+		{"'" + long, strings.Repeat("ő", MaxSheetNameLength-1)},
+		{strings.Repeat("á", MaxSheetNameLength-1) + "'x", strings.Repeat("á", MaxSheetNameLength-1)},
+	} {
+		got := SanitizeSheetName(tc.in)
+		if got != tc.want {
+			t.Errorf("SanitizeSheetName(%q): got %q, wanted %q", tc.in, got, tc.want)
+		}
+		if err := ValidateSheetName(got); err != nil {
+			t.Errorf("SanitizeSheetName(%q)=%q: %+v", tc.in, got, err)
+		}
+	}
+
+	for _, name := range []string{"", " ", "'", "''", "'a", "a'", "a/b", "a[1]", long} {
+		if err := ValidateSheetName(name); !errors.Is(err, ErrBadSheetName) {
+			t.Errorf("ValidateSheetName(%q): got %v", name, err)
+		}
+	}
+	if err := ValidateSheetName(long[:2*MaxSheetNameLength]); err != nil {
+		t.Errorf("%d runes: %+v", MaxSheetNameLength, err)
+	}
+
+	var sn SheetNames
+	if err := sn.Add("Data"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Data", "DATA", "data"} {
+		if err := sn.Add(name); !errors.Is(err, ErrDuplicateSheetName) {
+			t.Errorf("Add(%q): got %v", name, err)
+		}
+	}
+	if err := sn.Add("'x"); !errors.Is(err, ErrBadSheetName) {
+		t.Errorf("Add('x): got %v", err)
+	}
+
+	max := strings.Repeat("ű", MaxSheetNameLength)
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"dATa", "dATa (2)"},
+		{"data", "data (3)"},
+		{"Other", "Other"},
+		{"'", "Sheet"},
+		{"''", "Sheet (2)"},
+		{max, max},
+		{max, strings.Repeat("ű", MaxSheetNameLength-4) + " (2)"},
+		{strings.ToUpper(max), strings.Repeat("Ű", MaxSheetNameLength-4) + " (3)"},
+		{strings.Repeat("x", MaxSheetNameLength-4) + "'ab'", strings.Repeat("x", MaxSheetNameLength-4) + "'ab"},
+		{strings.Repeat("x", MaxSheetNameLength-4) + "'ab", strings.Repeat("x", MaxSheetNameLength-4) + " (2)"},
+	} {
+		got := sn.Unique(tc.in)
+		if got != tc.want {
+			t.Errorf("Unique(%q): got %q, wanted %q", tc.in, got, tc.want)
+		}
+		if n := utf8.RuneCountInString(got); n > MaxSheetNameLength {
+			t.Errorf("Unique(%q)=%q is %d long", tc.in, got, n)
+		}
+	}
+}
+
+func TestCellFor(t *testing.T) {
+	type myString string
+	loc := time.FixedZone("CEST", 2*3600)
+	for _, tc := range []struct {
+		v     any
+		typ   ValueType
+		value string
+	}{
+		{nil, StringType, ""},
+		{"a", StringType, "a"},
+		{[]byte("b"), StringType, "b"},
+		{myString("c"), StringType, "c"},
+		{true, BoolType, "true"},
+		{-1, IntType, "-1"},
+		{int8(-8), IntType, "-8"},
+		{int64(-1 << 63), IntType, "-9223372036854775808"},
+		{uint8(255), IntType, "255"},
+		{uint64(1<<64 - 1), IntType, "18446744073709551615"},
+		{float32(0.1), FloatType, "0.1"},
+		{1e21, FloatType, "1000000000000000000000"},
+		{time.Time{}, StringType, ""},
+		{time.Date(2023, 3, 31, 0, 0, 0, 0, loc), DateType, "2023-03-31"},
+		{time.Date(2023, 3, 31, 0, 0, 0, 1, loc), DateType, "2023-03-31T00:00:00"},
+		{time.Date(2023, 3, 31, 23, 30, 5, 0, loc), DateType, "2023-03-31T23:30:05"},
+		{90 * time.Second, StringType, "1m30s"},
+		{net.IPv4(127, 0, 0, 1), StringType, "127.0.0.1"},
+	} {
+		got := CellFor(tc.v)
+		if got.Type != tc.typ || got.Value != tc.value || got.Style != "" || got.Text != "" {
+			t.Errorf("%T(%v): got %+v, wanted %s %q", tc.v, tc.v, got, tc.typ, tc.value)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+// Pivot transforms the long-format rows (key, attribute, value) into wide rows:
+// one Row per distinct key (in the order of their first appearance), with the key
+// in the first column, and the values in the column of their attribute.
+//
+// The returned heading has an empty first cell, then the distinct attributes,
+// in the order of their first appearance.
+// Missing key-attribute combinations are empty cells, for repeated ones the last value wins.
+// The rows which are too short to have all three columns are skipped,
+// and a negative column index results in no rows at all.
+func Pivot(rows [][]string, keyCol, attrCol, valCol int) ([]Row, Row) {
+	if keyCol < 0 || attrCol < 0 || valCol < 0 {
+		return nil, NewTextRow("")
+	}
+	minLen := keyCol
+	if attrCol > minLen {
+		minLen = attrCol
+	}
+	if valCol > minLen {
+		minLen = valCol
+	}
+	var keys, attrs []string
+	keyIdx := make(map[string]int)
+	attrIdx := make(map[string]int)
+	var values [][]string // [key][attr]
+	for _, r := range rows {
+		if len(r) <= minLen {
+			continue
+		}
+		k, ok := keyIdx[r[keyCol]]
+		if !ok {
+			k = len(keys)
+			keyIdx[r[keyCol]] = k
+			keys = append(keys, r[keyCol])
+			values = append(values, nil)
+		}
+		a, ok := attrIdx[r[attrCol]]
+		if !ok {
+			a = len(attrs)
+			attrIdx[r[attrCol]] = a
+			attrs = append(attrs, r[attrCol])
+		}
+		if len(values[k]) <= a {
+			values[k] = append(values[k], make([]string, a+1-len(values[k]))...)
+		}
+		values[k][a] = r[valCol]
+	}
+
+	body := make([]Row, len(keys))
+	for i, k := range keys {
+		vv := make([]string, 1+len(attrs))
+		vv[0] = k
+		copy(vv[1:], values[i])
+		body[i] = NewTextRow(vv...)
+	}
+	return body, NewTextRow(append([]string{""}, attrs...)...)
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import (
+	"mime"
+	"net/http"
+)
+
+// ServeODS streams the document built by build to w, as an attachment with the given filename.
+//
+// The response is flushed as the zip is written, after its first 64KiB.
+// If build returns an error before that, a 500 Internal Server Error is sent instead.
+// After that, the response is aborted (the connection is closed, or the handler panics
+// with http.ErrAbortHandler if w cannot be hijacked), so the client sees a broken
+// transfer instead of a truncated, but valid document.
+func ServeODS(w http.ResponseWriter, filename string, build func(*ODSWriter) error) error {
+	hdr := w.Header()
+	hdr.Set("Content-Type", mimeType)
+	if cd := mime.FormatMediaType("attachment", map[string]string{"filename": filename}); cd != "" {
+		hdr.Set("Content-Disposition", cd)
+	} else {
+		hdr.Set("Content-Disposition", "attachment")
+	}
+	fw := &flushWriter{w: w}
+	fw.flusher, _ = w.(http.Flusher)
+	ow, err := NewWriter(fw)
+	if err == nil {
+		// on error, the zip is not finished, to not to produce a valid, but truncated document
+		if err = build(ow); err == nil {
+			if err = ow.Close(); err == nil {
+				err = fw.commit()
+			}
+		}
+	}
+	if err == nil {
+		return nil
+	}
+	if fw.committed {
+		abortResponse(w)
+		return err
+	}
+	hdr.Del("Content-Disposition")
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+	return err
+}
+
+// abortResponse closes the connection of w, or panics with http.ErrAbortHandler
+// if that's not possible (such as with HTTP/2).
+func abortResponse(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			conn.Close()
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// flushWriter holds back the first serveHoldBack bytes, then flushes after each Write
+// (the zip.Writer buffers already).
+type flushWriter struct {
+	w         http.ResponseWriter
+	flusher   http.Flusher
+	buf       []byte
+	committed bool
+}
+
+const serveHoldBack = 64 << 10
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	if !fw.committed {
+		if len(fw.buf)+len(p) <= serveHoldBack {
+			fw.buf = append(fw.buf, p...)
+			return len(p), nil
+		}
+		if err := fw.commit(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := fw.w.Write(p)
+	if fw.flusher != nil {
+		fw.flusher.Flush()
+	}
+	return n, err
+}
+
+// commit writes out the held back bytes.
+func (fw *flushWriter) commit() error {
+	if fw.committed {
+		return nil
+	}
+	fw.committed = true
+	_, err := fw.w.Write(fw.buf)
+	fw.buf = nil
+	if fw.flusher != nil {
+		fw.flusher.Flush()
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package ods
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSheetNameLength is the maximum length (in characters) of a sheet name.
+const MaxSheetNameLength = 31
+
+var (
+	// ErrBadSheetName is returned for sheet names LibreOffice won't accept.
+	ErrBadSheetName = errors.New("bad sheet name")
+	// ErrDuplicateSheetName is returned when a sheet name is already used.
+	ErrDuplicateSheetName = errors.New("duplicate sheet name")
+)
+
+const illegalSheetNameChars = `[]*?:/\`
+
+// SanitizeSheetName replaces the characters illegal in a sheet name with '_',
+// strips the leading and trailing apostrophes and truncates it to MaxSheetNameLength characters.
+//
+// An empty name becomes "Sheet".
+func SanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(illegalSheetNameChars, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if utf8.RuneCountInString(name) > MaxSheetNameLength {
+		name = string([]rune(name)[:MaxSheetNameLength])
+	}
+	name = strings.Trim(name, "'")
+	if strings.TrimSpace(name) == "" {
+		return "Sheet"
+	}
+	return name
+}
+
+// ValidateSheetName returns ErrBadSheetName if LibreOffice would reject the name.
+func ValidateSheetName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("empty: %w", ErrBadSheetName)
+	}
+	if utf8.RuneCountInString(name) > MaxSheetNameLength {
+		return fmt.Errorf("%q is longer than %d: %w", name, MaxSheetNameLength, ErrBadSheetName)
+	}
+	if i := strings.IndexAny(name, illegalSheetNameChars); i >= 0 {
+		return fmt.Errorf("%q contains %q: %w", name, name[i], ErrBadSheetName)
+	}
+	if strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") {
+		return fmt.Errorf("%q starts or ends with an apostrophe: %w", name, ErrBadSheetName)
+	}
+	return nil
+}
+
+// SheetNames keeps track of the sheet names used in a document.
+// Sheet names are compared case-insensitively, as LibreOffice does.
+//
+// The zero value is ready to use.
+type SheetNames struct {
+	seen map[string]struct{}
+}
+
+// Add the name, returning an error if it is invalid (ErrBadSheetName)
+// or already used (ErrDuplicateSheetName).
+func (sn *SheetNames) Add(name string) error {
+	if err := ValidateSheetName(name); err != nil {
+		return err
+	}
+	key := strings.ToLower(name)
+	if _, ok := sn.seen[key]; ok {
+		return fmt.Errorf("%q: %w", name, ErrDuplicateSheetName)
+	}
+	if sn.seen == nil {
+		sn.seen = make(map[string]struct{})
+	}
+	sn.seen[key] = struct{}{}
+	return nil
+}
+
+// Unique returns the sanitized name, made unique by a " (2)", " (3)"... suffix if needed, and adds it.
+func (sn *SheetNames) Unique(name string) string {
+	name = SanitizeSheetName(name)
+	candidate := name
+	for i := 2; sn.Add(candidate) != nil; i++ {
+		suffix := " (" + strconv.Itoa(i) + ")"
+		base := []rune(name)
+		if n := MaxSheetNameLength - utf8.RuneCountInString(suffix); len(base) > n {
+			base = base[:n]
+		}
+		candidate = strings.Trim(string(base), "'") + suffix
+	}
+	return candidate
+}
@@ -0,0 +1,238 @@
+// Code generated by qtc from "styles.xml.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:1
+package ods
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:1
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:1
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:1
+func StreamStyles(qw422016 *qt422016.Writer, pl PageLayout) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:1
+	qw422016.N().S(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:xlink="http://www.w3.org/1999/xlink" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0" xmlns:chart="urn:oasis:names:tc:opendocument:xmlns:chart:1.0" xmlns:dr3d="urn:oasis:names:tc:opendocument:xmlns:dr3d:1.0" xmlns:config="urn:oasis:names:tc:opendocument:xmlns:config:1.0" xmlns:math="http://www.w3.org/1998/Math/MathML" xmlns:form="urn:oasis:names:tc:opendocument:xmlns:form:1.0" xmlns:script="urn:oasis:names:tc:opendocument:xmlns:script:1.0" xmlns:ooo="http://openoffice.org/2004/office" xmlns:ooow="http://openoffice.org/2004/writer" xmlns:oooc="http://openoffice.org/2004/calc" xmlns:tableooo="http://openoffice.org/2009/table" xmlns:of="urn:oasis:names:tc:opendocument:xmlns:of:1.2" xmlns:dom="http://www.w3.org/2001/xml-events" xmlns:xforms="http://www.w3.org/2002/xforms" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:gnm="http://www.gnumeric.org/odf-extension/1.0" xmlns:css3t="http://www.w3.org/TR/css3-text/" xmlns:loext="urn:org:documentfoundation:names:experimental:office:xmlns:loext:1.0" xmlns:calcext="urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0" office:version="1.2">
+`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:3
+	StreamCommonStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:3
+	qw422016.N().S(`
+  <office:automatic-styles>
+`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:5
+	StreamPageLayoutStyle(qw422016, pl)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:5
+	qw422016.N().S(`
+  </office:automatic-styles>
+`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:7
+	StreamMasterStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:7
+	qw422016.N().S(`
+</office:document-styles>
+`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+func WriteStyles(qq422016 qtio422016.Writer, pl PageLayout) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	StreamStyles(qw422016, pl)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+func Styles(pl PageLayout) string {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	WriteStyles(qb422016, pl)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:9
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:11
+func StreamCommonStyles(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:11
+	qw422016.N().S(`<office:styles><number:number-style style:name="ND-0"><number:number number:decimal-places="0" number:grouping="true" number:min-integer-digits="1"/></number:number-style><number:date-style style:name="ND-1" number:format-source="fixed"><number:year number:style="long" number:calendar="gregorian"/><number:text>-</number:text><number:month number:possessive-form="false" number:textual="false" number:style="long"/></number:date-style>    <style:style style:name="Gnumeric-default" style:family="table-cell" style:data-style-name="General">
+      <style:table-cell-properties fo:background-color="transparent" fo:border-top="0.000cm none #c7c7c7" fo:border-bottom="0.000cm none #c7c7c7" fo:border-left="0.000cm none #c7c7c7" fo:border-right="0.000cm none #c7c7c7" style:diagonal-bl-tr="0.000cm none #c7c7c7" style:diagonal-tl-br="0.000cm none #c7c7c7" style:vertical-align="bottom" fo:wrap-option="no-wrap" style:shrink-to-fit="false" style:writing-mode="page" style:glyph-orientation-vertical="auto" style:cell-protect="protected" style:rotation-align="none" style:rotation-angle="0" style:print-content="true" style:decimal-places="13" style:text-align-source="value-type" style:repeat-content="false"/>
+      <style:paragraph-properties style:writing-mode-automatic="true" fo:margin-left="0pt"/>
+      <style:text-properties text:display="true" fo:font-weight="normal" fo:font-style="normal" style:text-line-through-type="none" style:text-line-through-style="none" style:text-underline-type="none" style:text-underline-style="none" style:text-underline-width="auto" style:text-underline-color="font-color" style:text-underline-mode="continuous" style:text-position="0% 100%" fo:font-size="10pt" fo:color="#000000" fo:font-family="Sans"/>
+    </style:style>
+    <style:default-style style:family="table-cell">
+      <style:table-cell-properties fo:background-color="transparent" fo:border-top="0.000cm none #c7c7c7" fo:border-bottom="0.000cm none #c7c7c7" fo:border-left="0.000cm none #c7c7c7" fo:border-right="0.000cm none #c7c7c7" style:diagonal-bl-tr="0.000cm none #c7c7c7" style:diagonal-tl-br="0.000cm none #c7c7c7" style:vertical-align="bottom" fo:wrap-option="no-wrap" style:shrink-to-fit="false" style:writing-mode="page" style:glyph-orientation-vertical="auto" style:cell-protect="protected" style:rotation-align="none" style:rotation-angle="0" style:print-content="true" style:decimal-places="13" style:text-align-source="value-type" style:repeat-content="false"/>
+      <style:paragraph-properties style:writing-mode-automatic="true" fo:margin-left="0pt"/>
+      <style:text-properties text:display="true" fo:font-weight="normal" fo:font-style="normal" style:text-line-through-type="none" style:text-line-through-style="none" style:text-underline-type="none" style:text-underline-style="none" style:text-underline-width="auto" style:text-underline-color="font-color" style:text-underline-mode="continuous" style:text-position="0% 100%" fo:font-size="10pt" fo:color="#000000" fo:font-family="Sans"/>
+    </style:default-style>
+    <style:default-style style:family="table-column">
+      <style:table-column-properties style:column-width="48pt" style:use-optimal-column-width="true"/>
+    </style:default-style>
+    <style:default-style style:family="table-row">
+      <style:table-row-properties style:row-height="12.75pt" style:use-optimal-row-height="true"/>
+    </style:default-style>
+  </office:styles>
+`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+func WriteCommonStyles(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	StreamCommonStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+func CommonStyles() string {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	WriteCommonStyles(qb422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:28
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:30
+func StreamPageLayoutStyle(qw422016 *qt422016.Writer, pl PageLayout) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:30
+	qw422016.N().S(`<style:page-layout style:name="pl-0" style:page-usage="all">
+      <style:page-layout-properties fo:margin-top="`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().F(pl.margin(pl.MarginTop))
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().S(`pt" fo:margin-bottom="`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().F(pl.margin(pl.MarginBottom))
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().S(`pt" fo:margin-left="`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().F(pl.margin(pl.MarginLeft))
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().S(`pt" fo:margin-right="`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().F(pl.margin(pl.MarginRight))
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().S(`pt" fo:page-width="`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().F(pl.width())
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().S(`pt" fo:page-height="`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().F(pl.height())
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().S(`pt" style:table-centering="none" style:print-page-order="ttb" style:writing-mode="lr-tb" style:print-orientation="`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.E().S(pl.orientation())
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:31
+	qw422016.N().S(`" style:print="charts drawings objects annotations" style:scale-to="100.00%"/>
+      <style:header-style>
+        <style:header-footer-properties fo:border="none" style:shadow="none" fo:padding="0pt" fo:margin="0pt" fo:min-height="48pt" svg:height="48pt" style:dynamic-spacing="true"/>
+      </style:header-style>
+      <style:footer-style>
+        <style:header-footer-properties fo:border="none" style:shadow="none" fo:padding="0pt" fo:margin="0pt" fo:min-height="48pt" svg:height="48pt" style:dynamic-spacing="true"/>
+      </style:footer-style>
+    </style:page-layout>
+`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+func WritePageLayoutStyle(qq422016 qtio422016.Writer, pl PageLayout) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	StreamPageLayoutStyle(qw422016, pl)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+func PageLayoutStyle(pl PageLayout) string {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	WritePageLayoutStyle(qb422016, pl)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:39
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:41
+func StreamMasterStyles(qw422016 *qt422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:41
+	qw422016.N().S(`<office:master-styles>
+    <style:master-page style:name="ta-mp-0" style:display-name="Sheet1" style:page-layout-name="pl-0">
+      <style:header style:display="true">
+        <style:region-left><text:p/></style:region-left>
+        <style:region-center><text:p><text:sheet-name/></text:p></style:region-center>
+        <style:region-right><text:p/></style:region-right>
+      </style:header>
+      <style:footer style:display="true">
+        <style:region-left><text:p/></style:region-left>
+        <style:region-center><text:p><text:span>Page </text:span><text:page-number style:num-format="1"/></text:p></style:region-center>
+        <style:region-right><text:p/></style:region-right>
+      </style:footer>
+    </style:master-page>
+  </office:master-styles>
+`)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+func WriteMasterStyles(qq422016 qtio422016.Writer) {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	StreamMasterStyles(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	qt422016.ReleaseWriter(qw422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+}
+
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+func MasterStyles() string {
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	qb422016 := qt422016.AcquireByteBuffer()
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	WriteMasterStyles(qb422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	qs422016 := string(qb422016.B)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	qt422016.ReleaseByteBuffer(qb422016)
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+	return qs422016
+//line src/github.com/tgulacsi/go/ods/styles.xml.qtpl:55
+}
@@ -0,0 +1,256 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// app is a managed program, with its last focused PID and its STOP timer.
+type app struct {
+	timer     *time.Timer
+	throttler *throttler
+	Name      string
+	pid       int
+	depth     int
+	// timeout overrides the STOP timeout (-t), if not zero.
+	timeout time.Duration
+	mu      sync.Mutex
+	// exempt from STOP, while it plays a video (see -no-stop-while-playing)
+	exempt bool
+	// paused is true after a STOP (or while throttled), till the CONT
+	paused bool
+	// since is the start of the management,
+	// pausedAt is the time of the last STOP (while paused),
+	// pausedFor accumulates the closed STOP-CONT intervals.
+	since, pausedAt time.Time
+	pausedFor       time.Duration
+}
+
+// apps is the list of the managed programs.
+type apps []*app
+
+// parseApps parses the comma-separated list of program names (app_id),
+// each with an optional STOP timeout override: "firefox:30s,thunderbird:2s".
+func parseApps(s string, stopDepth int) (apps, error) {
+	var as apps
+	for _, name := range strings.Split(s, ",") {
+		name, timeout, _ := strings.Cut(name, ":")
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		a := app{Name: name, depth: stopDepth, since: time.Now()}
+		if timeout = strings.TrimSpace(timeout); timeout != "" {
+			var err error
+			if a.timeout, err = time.ParseDuration(timeout); err != nil {
+				return as, fmt.Errorf("timeout of %q: %w", name, err)
+			}
+		}
+		as = append(as, &a)
+	}
+	return as, nil
+}
+
+func (a *app) logger(reason string) *slog.Logger {
+	return logger.With("prog", a.Name, "reason", reason)
+}
+
+// find the app by its app_id.
+//
+// A Name without a dot matches the last part of a reverse-DNS app_id
+// (a flatpak app_id or a macOS bundle id), too: "firefox" matches "org.mozilla.firefox".
+func (as apps) find(appID string) *app {
+	last := appID[strings.LastIndexByte(appID, '.')+1:]
+	for _, a := range as {
+		if strings.EqualFold(a.Name, appID) ||
+			!strings.Contains(a.Name, ".") && strings.EqualFold(a.Name, last) {
+			return a
+		}
+	}
+	return nil
+}
+
+// focus records the PID and CONTinues the app.
+func (a *app) focus(pid int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.unthrottle()
+	a.pid = pid
+	a.kill("focus", false, 999)
+	a.continued()
+}
+
+// scheduleStop (re)starts the timer to STOP the app after its own timeout,
+// or the given (default) one.
+func (a *app) scheduleStop(timeout time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pid == 0 {
+		return
+	}
+	if a.timeout != 0 {
+		timeout = a.timeout
+	}
+	if a.timer == nil {
+		a.timer = time.AfterFunc(timeout, a.stop)
+		return
+	}
+	a.timer.Stop()
+	a.timer.Reset(timeout)
+}
+
+// stop STOPs the app, or starts throttling it, if throttleDuty is set.
+func (a *app) stop() { a.stopFor("timeout") }
+
+// stopFor is stop, logging the given reason.
+func (a *app) stopFor(reason string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pid == 0 {
+		return
+	}
+	if a.exempt {
+		a.logger(reason).Info("skip STOP", "pid", a.pid, "exempt", "playing")
+		return
+	}
+	if throttleDuty <= 0 {
+		if a.kill(reason, true, a.depth) {
+			return
+		}
+	} else if a.throttler == nil {
+		a.throttler = startThrottle(a.logger("throttle"), a.pid, a.depth, throttleDuty, throttlePeriod)
+	}
+	if !a.paused {
+		a.paused, a.pausedAt = true, time.Now()
+		bus.emit("Stopped", a.Name, a.pid)
+	}
+}
+
+// kill STOPs or CONTinues the app's process tree - must be called with a.mu held.
+//
+// If the process has exited, it forgets the PID, and reports true.
+func (a *app) kill(reason string, stop bool, depth int) bool {
+	if err := kill(a.logger(reason), a.pid, stop, depth, false); !isGone(err) {
+		return false
+	}
+	a.logger(reason).Debug("forget exited", "pid", a.pid)
+	a.pid = 0
+	return true
+}
+
+// continued records the CONT, emitting the signal - must be called with a.mu held.
+func (a *app) continued() {
+	if a.paused {
+		d := time.Since(a.pausedAt)
+		a.paused, a.pausedFor = false, a.pausedFor+d
+		a.logger("continued").Debug("was stopped", "pid", a.pid, "for", d)
+		bus.emit("Continued", a.Name, a.pid)
+	}
+}
+
+// stoppedFor returns the cumulative time the app has been STOPped (or throttled),
+// and the time since it is managed.
+func (a *app) stoppedFor() (stopped, total time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stoppedForLocked()
+}
+
+func (a *app) stoppedForLocked() (stopped, total time.Duration) {
+	now := time.Now()
+	stopped = a.pausedFor
+	if a.paused {
+		stopped += now.Sub(a.pausedAt)
+	}
+	return stopped, now.Sub(a.since)
+}
+
+// summary returns "firefox stopped 4m12s over 1h".
+func summary(name string, stopped, total time.Duration) string {
+	return name + " stopped " + shortDuration(stopped) + " over " + shortDuration(total)
+}
+
+// shortDuration formats d rounded to seconds, without the zero trailing units: "1h", "4m12s".
+func shortDuration(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// status returns the summary, the state and the last focused PID of the app:
+// "firefox stopped 4m12s over 1h (running, pid 1234)".
+func (a *app) status() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stopped, total := a.stoppedForLocked()
+	state := "running"
+	switch {
+	case a.pid == 0:
+		state = "unknown"
+	case a.exempt:
+		state = "exempt"
+	case a.throttler != nil:
+		state = "throttled"
+	case a.paused:
+		state = "stopped"
+	}
+	return summary(a.Name, stopped, total) + " (" + state + ", pid " + strconv.Itoa(a.pid) + ")"
+}
+
+// setExempt sets whether the app is exempt from STOP, and reports whether it has changed.
+// An exempt app is CONTinued.
+func (a *app) setExempt(exempt bool) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.exempt == exempt {
+		return false
+	}
+	a.exempt = exempt
+	if exempt {
+		if a.timer != nil {
+			a.timer.Stop()
+		}
+		a.unthrottle()
+		if a.pid != 0 {
+			a.kill("playing", false, 999)
+			a.continued()
+		}
+	}
+	return true
+}
+
+// unthrottle stops the throttler - must be called with a.mu held.
+func (a *app) unthrottle() {
+	if a.throttler != nil {
+		a.throttler.Stop()
+		a.throttler = nil
+	}
+}
+
+// resume stops the timer and CONTinues the app, logging the reason.
+func (a *app) resume(reason string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.unthrottle()
+	if a.pid != 0 {
+		a.kill(reason, false, 999)
+		a.continued()
+	}
+}
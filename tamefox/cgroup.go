@@ -0,0 +1,110 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot and procRoot are the mount points of cgroup2 and procfs (variables for the tests).
+var (
+	cgroupRoot = "/sys/fs/cgroup"
+	procRoot   = "/proc"
+)
+
+var errNoFreezer = errors.New("no cgroup v2 freezer")
+
+// freezeCgroup freezes (or thaws) the cgroup v2 of pid, by writing cgroup.freeze.
+//
+// This is atomic for the whole cgroup, so it does not race with spawning children,
+// but refuses to freeze our own cgroup (when the program is not in its own scope),
+// and any cgroup with processes outside of the process tree of pid
+// (such as a session scope shared with the compositor), see ownsCgroup.
+func freezeCgroup(pid int, freeze bool) error {
+	cg, err := cgroupOf(pid)
+	if err != nil {
+		return err
+	}
+	if own, _ := cgroupOf(self); own == cg {
+		return fmt.Errorf("%d is in our cgroup %q: %w", pid, cg, errNoFreezer)
+	}
+	fn := filepath.Join(cgroupRoot, cg, "cgroup.freeze")
+	if _, err = os.Stat(fn); err != nil {
+		return fmt.Errorf("%s: %w", err, errNoFreezer)
+	}
+	if freeze {
+		if err = ownsCgroup(pid, cg); err != nil {
+			return err
+		}
+	}
+	v := []byte("0")
+	if freeze {
+		v[0] = '1'
+	}
+	if dryRun {
+		logger.Info("dry-run", "write", fn, "value", string(v))
+		return nil
+	}
+	if err = os.WriteFile(fn, v, 0644); err != nil {
+		return fmt.Errorf("write %q: %w", fn, err)
+	}
+	return nil
+}
+
+// ownsCgroup returns nil if all the processes of the cgroup are pid or its descendants,
+// errNoFreezer otherwise.
+func ownsCgroup(pid int, cg string) error {
+	b, err := os.ReadFile(filepath.Join(cgroupRoot, cg, "cgroup.procs"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", err, errNoFreezer)
+	}
+	for _, f := range strings.Fields(string(b)) {
+		p, err := strconv.Atoi(f)
+		if err != nil {
+			return fmt.Errorf("%q: %s: %w", cg, err, errNoFreezer)
+		}
+		if !isDescendantOf(p, pid) {
+			return fmt.Errorf("%d in %q is not a descendant of %d: %w", p, cg, pid, errNoFreezer)
+		}
+	}
+	return nil
+}
+
+// isDescendantOf reports whether p is pid, or one of its descendants.
+func isDescendantOf(p, pid int) bool {
+	for i := 0; i < 1024 && p > 1; i++ {
+		if p == pid {
+			return true
+		}
+		var err error
+		if p, err = getPPid(p); err != nil {
+			return false
+		}
+	}
+	return p == pid
+}
+
+// cgroupOf returns the cgroup v2 path of pid, read from /proc/<pid>/cgroup.
+func cgroupOf(pid int) (string, error) {
+	b, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		// cgroup v2 is "0::/path"
+		if line := scanner.Text(); strings.HasPrefix(line, "0::") {
+			if cg := line[3:]; cg != "/" && cg != "" {
+				return cg, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%d: %w", pid, errNoFreezer)
+}
@@ -0,0 +1,113 @@
+//go:build !darwin
+// +build !darwin
+
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// fakeRoots sets up procRoot and cgroupRoot in a temp dir,
+// with the processes (pid: {ppid, cgroup}) and the cgroups.
+func fakeRoots(t *testing.T, procs map[int]struct {
+	ppid int
+	cg   string
+}) {
+	t.Helper()
+	oldProc, oldCgroup := procRoot, cgroupRoot
+	t.Cleanup(func() { procRoot, cgroupRoot = oldProc, oldCgroup })
+	dir := t.TempDir()
+	procRoot, cgroupRoot = filepath.Join(dir, "proc"), filepath.Join(dir, "cgroup")
+	members := make(map[string]string)
+	for pid, p := range procs {
+		pd := filepath.Join(procRoot, strconv.Itoa(pid))
+		if err := os.MkdirAll(pd, 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pd, "status"),
+			[]byte("Name:\tx\nPid:\t"+strconv.Itoa(pid)+"\nPPid:\t"+strconv.Itoa(p.ppid)+"\n"), 0640); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pd, "cgroup"), []byte("0::"+p.cg+"\n"), 0640); err != nil {
+			t.Fatal(err)
+		}
+		members[p.cg] += strconv.Itoa(pid) + "\n"
+	}
+	for cg, procs := range members {
+		cd := filepath.Join(cgroupRoot, cg)
+		if err := os.MkdirAll(cd, 0750); err != nil {
+			t.Fatal(err)
+		}
+		for fn, content := range map[string]string{"cgroup.procs": procs, "cgroup.freeze": "0\n"} {
+			if err := os.WriteFile(filepath.Join(cd, fn), []byte(content), 0640); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestCgroupOf(t *testing.T) {
+	const appScope = "/user.slice/user-1000.slice/user@1000.service/app.slice/app-firefox-1.scope"
+	fakeRoots(t, map[int]struct {
+		ppid int
+		cg   string
+	}{100: {1, appScope}, 200: {1, "/"}})
+	if cg, err := cgroupOf(100); err != nil || cg != appScope {
+		t.Errorf("got %q, %+v; wanted %q", cg, err, appScope)
+	}
+	if cg, err := cgroupOf(200); !errors.Is(err, errNoFreezer) {
+		t.Errorf("root cgroup: got %q, %+v", cg, err)
+	}
+	if _, err := cgroupOf(300); err == nil {
+		t.Error("missing process: wanted error")
+	}
+}
+
+func TestFreezeCgroupGuard(t *testing.T) {
+	const (
+		session = "/user.slice/user-1000.slice/session-2.scope"
+		app     = "/user.slice/user-1000.slice/user@1000.service/app.slice/app-firefox-1.scope"
+	)
+	fakeRoots(t, map[int]struct {
+		ppid int
+		cg   string
+	}{
+		// sway and firefox (launched by it) in the session scope
+		10: {1, session}, 11: {10, session}, 12: {11, session},
+		// firefox and its children in its own scope
+		20: {1, app}, 21: {20, app}, 22: {21, app},
+	})
+	readFreeze := func(cg string) string {
+		b, err := os.ReadFile(filepath.Join(cgroupRoot, cg, "cgroup.freeze"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	if err := freezeCgroup(11, true); !errors.Is(err, errNoFreezer) {
+		t.Errorf("shared session scope: got %+v, wanted errNoFreezer", err)
+	}
+	if got := readFreeze(session); got != "0\n" {
+		t.Errorf("session scope is frozen: %q", got)
+	}
+
+	if err := freezeCgroup(20, true); err != nil {
+		t.Fatalf("own app scope: %+v", err)
+	}
+	if got := readFreeze(app); got != "1" {
+		t.Errorf("app scope: got %q, wanted 1", got)
+	}
+	if err := freezeCgroup(21, false); err != nil {
+		t.Fatalf("thaw: %+v", err)
+	}
+	if got := readFreeze(app); got != "0" {
+		t.Errorf("app scope: got %q, wanted 0", got)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// configAliases maps the more readable config keys to flag names.
+var configAliases = map[string]string{
+	"timeout": "t",
+	"verbose": "v",
+}
+
+// loadConfig reads the TOML (or JSON, for .json files) config file,
+// and sets the flags of fs that have not been set on the command line.
+//
+// The keys are the flag names (such as "prog", "stop-depth", "ac", "battery-threshold"),
+// lists are joined with commas.
+func loadConfig(fs *flag.FlagSet, fn string) error {
+	var m map[string]interface{}
+	if strings.EqualFold(filepath.Ext(fn), ".json") {
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(b, &m); err != nil {
+			return fmt.Errorf("parse %q: %w", fn, err)
+		}
+	} else if _, err := toml.DecodeFile(fn, &m); err != nil {
+		return fmt.Errorf("parse %q: %w", fn, err)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for k, v := range m {
+		name := k
+		if a, ok := configAliases[k]; ok {
+			name = a
+		}
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("%s: unknown key %q", fn, k)
+		}
+		if set[name] {
+			continue
+		}
+		var s string
+		switch x := v.(type) {
+		case []interface{}:
+			ss := make([]string, len(x))
+			for i, e := range x {
+				ss[i] = fmt.Sprint(e)
+			}
+			s = strings.Join(ss, ",")
+		default:
+			s = fmt.Sprint(x)
+		}
+		if err := fs.Set(name, s); err != nil {
+			return fmt.Errorf("%s: %s=%q: %w", fn, k, s, err)
+		}
+	}
+	return nil
+}
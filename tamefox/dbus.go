@@ -0,0 +1,546 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A minimal D-Bus (session bus) service, see
+// https://dbus.freedesktop.org/doc/dbus-specification.html
+//
+// It only knows what tamefox needs: EXTERNAL auth, method calls with
+// string arguments, string replies, and signals with (su) arguments.
+// For example:
+//
+//	busctl --user call com.github.tgulacsi.tamefox /com/github/tgulacsi/tamefox com.github.tgulacsi.tamefox Resume s firefox
+const (
+	dbusName      = "com.github.tgulacsi.tamefox"
+	dbusPath      = "/com/github/tgulacsi/tamefox"
+	dbusInterface = dbusName
+
+	dbusIntrospection = `<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd">
+<node>
+  <interface name="` + dbusInterface + `">
+    <method name="Resume"><arg name="prog" type="s" direction="in"/></method>
+    <method name="Stop"><arg name="prog" type="s" direction="in"/></method>
+    <method name="Status"><arg name="status" type="s" direction="out"/></method>
+    <signal name="Stopped"><arg name="prog" type="s"/><arg name="pid" type="u"/></signal>
+    <signal name="Continued"><arg name="prog" type="s"/><arg name="pid" type="u"/></signal>
+  </interface>
+  <interface name="org.freedesktop.DBus.Introspectable">
+    <method name="Introspect"><arg name="xml" type="s" direction="out"/></method>
+  </interface>
+</node>`
+)
+
+// D-Bus message types and header field codes.
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+	dbusSignal       = 4
+
+	dbusNoReplyExpected = 0x1
+
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSender      = 7
+	dbusFieldSignature   = 8
+)
+
+// bus is the D-Bus service, if enabled (-dbus).
+var bus *dbusService
+
+type dbusService struct {
+	conn    net.Conn
+	br      *bufio.Reader
+	managed apps
+	serial  uint32
+	mu      sync.Mutex
+}
+
+type dbusMessage struct {
+	Path, Interface, Member, ErrorName, Destination, Sender, Signature string
+
+	Body        []byte
+	order       binary.ByteOrder
+	Serial      uint32
+	ReplySerial uint32
+	Type, Flags byte
+}
+
+// newDBusService connects to the session bus and registers dbusName.
+// It serves the method calls (Resume, Stop, Status) for the managed apps till ctx is done.
+func newDBusService(ctx context.Context, managed apps) (*dbusService, error) {
+	s, err := dialDBus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn := s.conn
+	s.managed = managed
+	// DO_NOT_QUEUE
+	reply, err := s.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "RequestName",
+		dbusName, uint32(4))
+	if err == nil && (len(reply.Body) < 4 || reply.order.Uint32(reply.Body) != 1) {
+		err = errors.New("the name is already taken")
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("RequestName %q: %w", dbusName, err)
+	}
+	go func() { <-ctx.Done(); conn.Close() }()
+	go s.serve(ctx)
+	return s, nil
+}
+
+// dialDBus connects to the session bus, and says Hello.
+func dialDBus(ctx context.Context) (*dbusService, error) {
+	addr, err := dbusSessionAddress()
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %q: %w", addr, err)
+	}
+	s := &dbusService{conn: conn, br: bufio.NewReader(conn)}
+	if err = s.auth(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	if _, err = s.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Hello: %w", err)
+	}
+	return s, nil
+}
+
+// dbusStatus calls the Status method of the running tamefox (see -dbus).
+func dbusStatus(ctx context.Context) (string, error) {
+	s, err := dialDBus(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer s.conn.Close()
+	reply, err := s.call(dbusName, dbusPath, dbusInterface, "Status")
+	if err != nil {
+		return "", fmt.Errorf("Status: %w", err)
+	}
+	return reply.firstString()
+}
+
+// dbusSessionAddress returns the socket address of the session bus,
+// from DBUS_SESSION_BUS_ADDRESS, or $XDG_RUNTIME_DIR/bus.
+func dbusSessionAddress() (string, error) {
+	env := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	for _, addr := range strings.Split(env, ";") {
+		transport, params, ok := strings.Cut(addr, ":")
+		if !ok || transport != "unix" {
+			continue
+		}
+		for _, kv := range strings.Split(params, ",") {
+			switch k, v, _ := strings.Cut(kv, "="); k {
+			case "path":
+				return v, nil
+			case "abstract":
+				return "@" + v, nil
+			}
+		}
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir + "/bus", nil
+	}
+	return "", fmt.Errorf("no usable unix address in DBUS_SESSION_BUS_ADDRESS=%q", env)
+}
+
+// auth authenticates with the EXTERNAL mechanism (the uid).
+func (s *dbusService) auth() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := io.WriteString(s.conn, "\x00AUTH EXTERNAL "+uid+"\r\n"); err != nil {
+		return err
+	}
+	line, err := s.br.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("rejected: %q", strings.TrimSpace(line))
+	}
+	_, err = io.WriteString(s.conn, "BEGIN\r\n")
+	return err
+}
+
+// call calls the method, and waits for its reply - only usable before serve is started.
+func (s *dbusService) call(dest, path, iface, member string, args ...interface{}) (dbusMessage, error) {
+	serial, err := s.send(dbusMessage{Type: dbusMethodCall,
+		Destination: dest, Path: path, Interface: iface, Member: member}, args...)
+	if err != nil {
+		return dbusMessage{}, err
+	}
+	for {
+		msg, err := s.read()
+		if err != nil {
+			return msg, err
+		}
+		if msg.ReplySerial != serial {
+			continue
+		}
+		if msg.Type == dbusError {
+			return msg, fmt.Errorf("%s: %s", msg.ErrorName, msg.errorMessage())
+		}
+		return msg, nil
+	}
+}
+
+// serve answers the method calls, till the connection is closed.
+func (s *dbusService) serve(ctx context.Context) {
+	for {
+		msg, err := s.read()
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("dbus", "error", err)
+			}
+			return
+		}
+		if msg.Type != dbusMethodCall {
+			continue
+		}
+		reply, errName, errMsg := s.handle(msg)
+		if msg.Flags&dbusNoReplyExpected != 0 {
+			continue
+		}
+		resp := dbusMessage{Type: dbusMethodReturn, Destination: msg.Sender, ReplySerial: msg.Serial}
+		var args []interface{}
+		if errName != "" {
+			resp.Type, resp.ErrorName = dbusError, errName
+			args = append(args, errMsg)
+		} else if reply != "" {
+			args = append(args, reply)
+		}
+		if _, err = s.send(resp, args...); err != nil {
+			logger.Warn("dbus reply", "member", msg.Member, "error", err)
+		}
+	}
+}
+
+// handle the method call, returning the reply, or the error name and message.
+func (s *dbusService) handle(msg dbusMessage) (reply, errName, errMsg string) {
+	logger.Debug("dbus", "member", msg.Member, "sender", msg.Sender)
+	if msg.Interface == "org.freedesktop.DBus.Introspectable" && msg.Member == "Introspect" {
+		return dbusIntrospection, "", ""
+	}
+	if msg.Path != dbusPath || (msg.Interface != "" && msg.Interface != dbusInterface) {
+		return "", "org.freedesktop.DBus.Error.UnknownObject", msg.Path + " " + msg.Interface
+	}
+	var name string
+	switch msg.Member {
+	case "Resume", "Stop":
+		if msg.Signature != "s" {
+			return "", "org.freedesktop.DBus.Error.InvalidArgs", "wanted a program name (s), got " + msg.Signature
+		}
+		var err error
+		if name, err = msg.firstString(); err != nil {
+			return "", "org.freedesktop.DBus.Error.InvalidArgs", err.Error()
+		}
+	case "Status":
+		var buf strings.Builder
+		for _, a := range s.managed {
+			buf.WriteString(a.status())
+			buf.WriteByte('\n')
+		}
+		return buf.String(), "", ""
+	default:
+		return "", "org.freedesktop.DBus.Error.UnknownMethod", msg.Member
+	}
+	var found bool
+	for _, a := range s.managed {
+		if name != "" && !strings.EqualFold(a.Name, name) {
+			continue
+		}
+		found = true
+		if msg.Member == "Resume" {
+			a.resume("dbus")
+		} else {
+			a.stopFor("dbus")
+		}
+	}
+	if !found {
+		return "", dbusInterface + ".Error.UnknownProgram", name
+	}
+	return "", "", ""
+}
+
+// emit the signal (Stopped or Continued) of the program - a no-op if s is nil.
+func (s *dbusService) emit(member, name string, pid int) {
+	if s == nil {
+		return
+	}
+	if _, err := s.send(dbusMessage{Type: dbusSignal,
+		Path: dbusPath, Interface: dbusInterface, Member: member},
+		name, uint32(pid)); err != nil {
+		logger.Warn("dbus signal", "member", member, "error", err)
+	}
+}
+
+// send the message with the (string or uint32) arguments as its body, returning its serial.
+func (s *dbusService) send(msg dbusMessage, args ...interface{}) (uint32, error) {
+	var body dbusEncoder
+	var sig []byte
+	for _, a := range args {
+		switch x := a.(type) {
+		case string:
+			sig = append(sig, 's')
+			body.string(x)
+		case uint32:
+			sig = append(sig, 'u')
+			body.uint32(x)
+		default:
+			return 0, fmt.Errorf("unsupported argument type %T", a)
+		}
+	}
+	msg.Signature = string(sig)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serial++
+	msg.Serial = s.serial
+
+	var e dbusEncoder
+	e.b = append(e.b, 'l', msg.Type, msg.Flags, 1)
+	e.uint32(uint32(len(body.b)))
+	e.uint32(msg.Serial)
+	e.uint32(0) // length of the header fields array
+	e.align(8)
+	start := len(e.b)
+	for _, f := range []struct {
+		value string
+		code  byte
+		sig   byte
+	}{
+		{msg.Path, dbusFieldPath, 'o'},
+		{msg.Interface, dbusFieldInterface, 's'},
+		{msg.Member, dbusFieldMember, 's'},
+		{msg.ErrorName, dbusFieldErrorName, 's'},
+		{msg.Destination, dbusFieldDestination, 's'},
+		{msg.Signature, dbusFieldSignature, 'g'},
+	} {
+		if f.value == "" {
+			continue
+		}
+		e.align(8)
+		e.b = append(e.b, f.code, 1, f.sig, 0)
+		if f.sig == 'g' {
+			e.signature(f.value)
+		} else {
+			e.string(f.value)
+		}
+	}
+	if msg.ReplySerial != 0 {
+		e.align(8)
+		e.b = append(e.b, dbusFieldReplySerial, 1, 'u', 0)
+		e.uint32(msg.ReplySerial)
+	}
+	binary.LittleEndian.PutUint32(e.b[12:], uint32(len(e.b)-start))
+	e.align(8)
+	_, err := s.conn.Write(append(e.b, body.b...))
+	return msg.Serial, err
+}
+
+// read the next message.
+func (s *dbusService) read() (dbusMessage, error) {
+	var msg dbusMessage
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(s.br, fixed); err != nil {
+		return msg, err
+	}
+	switch fixed[0] {
+	case 'l':
+		msg.order = binary.LittleEndian
+	case 'B':
+		msg.order = binary.BigEndian
+	default:
+		return msg, fmt.Errorf("bad endianness %q", fixed[0])
+	}
+	msg.Type, msg.Flags = fixed[1], fixed[2]
+	bodyLen := msg.order.Uint32(fixed[4:])
+	msg.Serial = msg.order.Uint32(fixed[8:])
+	fieldsLen := msg.order.Uint32(fixed[12:])
+	// the maximum message length of the specification
+	const maxLen = 1 << 27
+	if bodyLen > maxLen || fieldsLen > maxLen || 16+uint64(fieldsLen)+uint64(bodyLen) > maxLen {
+		return msg, fmt.Errorf("message too long (%d+%d)", fieldsLen, bodyLen)
+	}
+	hdrLen := 16 + int(fieldsLen)
+	if rem := hdrLen % 8; rem != 0 {
+		hdrLen += 8 - rem
+	}
+	// do not trust the lengths for the allocation: read what is there
+	want := int64(hdrLen-16) + int64(bodyLen)
+	rest, err := io.ReadAll(io.LimitReader(s.br, want))
+	if err != nil {
+		return msg, err
+	}
+	if int64(len(rest)) != want {
+		return msg, fmt.Errorf("read %d bytes of %d: %w", len(rest), want, io.ErrUnexpectedEOF)
+	}
+	b := append(fixed, rest...)
+	msg.Body = b[hdrLen:]
+	d := dbusDecoder{b: b[:16+fieldsLen], pos: 16, order: msg.order}
+	for d.pos < len(d.b) {
+		d.align(8)
+		code, err := d.byte()
+		if err != nil {
+			return msg, err
+		}
+		sig, err := d.signature()
+		if err != nil {
+			return msg, err
+		}
+		var str string
+		var u uint32
+		switch sig {
+		case "s", "o":
+			str, err = d.string()
+		case "g":
+			str, err = d.signature()
+		case "u":
+			u, err = d.uint32()
+		default:
+			err = fmt.Errorf("unsupported header field type %q", sig)
+		}
+		if err != nil {
+			return msg, fmt.Errorf("header field %d: %w", code, err)
+		}
+		switch code {
+		case dbusFieldPath:
+			msg.Path = str
+		case dbusFieldInterface:
+			msg.Interface = str
+		case dbusFieldMember:
+			msg.Member = str
+		case dbusFieldErrorName:
+			msg.ErrorName = str
+		case dbusFieldReplySerial:
+			msg.ReplySerial = u
+		case dbusFieldDestination:
+			msg.Destination = str
+		case dbusFieldSender:
+			msg.Sender = str
+		case dbusFieldSignature:
+			msg.Signature = str
+		}
+	}
+	return msg, nil
+}
+
+// firstString returns the first (string) argument of the body.
+func (msg dbusMessage) firstString() (string, error) {
+	d := dbusDecoder{b: msg.Body, order: msg.order}
+	return d.string()
+}
+
+// errorMessage returns the message of an error reply.
+func (msg dbusMessage) errorMessage() string {
+	if !strings.HasPrefix(msg.Signature, "s") {
+		return ""
+	}
+	s, _ := msg.firstString()
+	return s
+}
+
+// dbusEncoder marshals little endian, aligned from the start of b.
+type dbusEncoder struct {
+	b []byte
+}
+
+func (e *dbusEncoder) align(n int) {
+	for len(e.b)%n != 0 {
+		e.b = append(e.b, 0)
+	}
+}
+func (e *dbusEncoder) uint32(u uint32) {
+	e.align(4)
+	e.b = binary.LittleEndian.AppendUint32(e.b, u)
+}
+func (e *dbusEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.b = append(append(e.b, s...), 0)
+}
+func (e *dbusEncoder) signature(s string) {
+	e.b = append(append(append(e.b, byte(len(s))), s...), 0)
+}
+
+// dbusDecoder unmarshals from b, aligned from its start.
+type dbusDecoder struct {
+	order binary.ByteOrder
+	b     []byte
+	pos   int
+}
+
+var errDBusShort = errors.New("dbus: message too short")
+
+func (d *dbusDecoder) align(n int) {
+	if rem := d.pos % n; rem != 0 {
+		d.pos += n - rem
+	}
+}
+func (d *dbusDecoder) byte() (byte, error) {
+	if d.pos >= len(d.b) {
+		return 0, errDBusShort
+	}
+	d.pos++
+	return d.b[d.pos-1], nil
+}
+func (d *dbusDecoder) uint32() (uint32, error) {
+	d.align(4)
+	if d.pos+4 > len(d.b) {
+		return 0, errDBusShort
+	}
+	d.pos += 4
+	return d.order.Uint32(d.b[d.pos-4:]), nil
+}
+func (d *dbusDecoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	return d.bytes(int(n))
+}
+func (d *dbusDecoder) signature() (string, error) {
+	n, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	return d.bytes(int(n))
+}
+
+// bytes returns the next n bytes as a string, skipping the terminating NUL.
+func (d *dbusDecoder) bytes(n int) (string, error) {
+	if n < 0 || d.pos+n+1 > len(d.b) {
+		return "", errDBusShort
+	}
+	if d.b[d.pos+n] != 0 {
+		return "", errors.New("dbus: missing NUL terminator")
+	}
+	s := string(d.b[d.pos : d.pos+n])
+	d.pos += n + 1
+	return s, nil
+}
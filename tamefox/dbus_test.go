@@ -0,0 +1,209 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+// sendRaw sends the message through a net.Pipe, returning the bytes on the wire.
+func sendRaw(t testing.TB, msg dbusMessage, args ...interface{}) []byte {
+	t.Helper()
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	done := make(chan error, 1)
+	go func() {
+		_, err := (&dbusService{conn: a}).send(msg, args...)
+		a.Close()
+		done <- err
+	}()
+	raw, err := io.ReadAll(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// checkAlignment checks the wire format independently of dbusDecoder:
+// each header field must start at a multiple of 8, and so must the body.
+func checkAlignment(t *testing.T, raw []byte) {
+	t.Helper()
+	if len(raw) < 16 || raw[0] != 'l' || raw[3] != 1 {
+		t.Fatalf("bad fixed header: %q", raw)
+	}
+	bodyLen := binary.LittleEndian.Uint32(raw[4:])
+	fieldsLen := binary.LittleEndian.Uint32(raw[12:])
+	end := 16 + int(fieldsLen)
+	for pos := 16; pos < end; {
+		if pos%8 != 0 {
+			t.Fatalf("header field at %d is not 8-aligned", pos)
+		}
+		code, sigLen := raw[pos], int(raw[pos+1])
+		sig := string(raw[pos+2 : pos+2+sigLen])
+		pos += 2 + sigLen + 1
+		switch sig {
+		case "s", "o":
+			if pos%4 != 0 {
+				pos += 4 - pos%4
+			}
+			n := int(binary.LittleEndian.Uint32(raw[pos:]))
+			if raw[pos+4+n] != 0 {
+				t.Errorf("field %d: no NUL terminator", code)
+			}
+			pos += 4 + n + 1
+		case "g":
+			pos += 1 + int(raw[pos]) + 1
+		case "u":
+			if pos%4 != 0 {
+				t.Errorf("field %d: uint32 at %d is not 4-aligned", code, pos)
+			}
+			pos += 4
+		default:
+			t.Fatalf("field %d: unknown signature %q", code, sig)
+		}
+		if pos < end && pos%8 != 0 {
+			pos += 8 - pos%8
+		}
+	}
+	bodyStart := end
+	if bodyStart%8 != 0 {
+		bodyStart += 8 - bodyStart%8
+	}
+	if got := len(raw) - bodyStart; got != int(bodyLen) {
+		t.Errorf("body is %d bytes at %d, wanted %d", got, bodyStart, bodyLen)
+	}
+}
+
+func readRaw(raw []byte) (dbusMessage, error) {
+	return (&dbusService{br: bufio.NewReader(bytes.NewReader(raw))}).read()
+}
+
+func TestDBusRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		msg  dbusMessage
+		args []interface{}
+	}{
+		{"call", dbusMessage{Type: dbusMethodCall, Destination: dbusName, Path: dbusPath, Interface: dbusInterface, Member: "Resume"},
+			[]interface{}{"firefox"}},
+		{"signal", dbusMessage{Type: dbusSignal, Path: dbusPath, Interface: dbusInterface, Member: "Stopped"},
+			[]interface{}{"firefox", uint32(1234)}},
+		{"return", dbusMessage{Type: dbusMethodReturn, Destination: ":1.42", ReplySerial: 7}, nil},
+		{"error", dbusMessage{Type: dbusError, Destination: ":1.42", ReplySerial: 3,
+			ErrorName: dbusInterface + ".Error.UnknownProgram"}, []interface{}{"chrome"}},
+	} {
+		raw := sendRaw(t, tc.msg, tc.args...)
+		checkAlignment(t, raw)
+		got, err := readRaw(raw)
+		if err != nil {
+			t.Fatalf("%s: %+v", tc.name, err)
+		}
+		want := tc.msg
+		if got.Type != want.Type || got.Path != want.Path || got.Interface != want.Interface ||
+			got.Member != want.Member || got.ErrorName != want.ErrorName ||
+			got.Destination != want.Destination || got.ReplySerial != want.ReplySerial || got.Serial != 1 {
+			t.Errorf("%s: got %+v, wanted %+v", tc.name, got, want)
+		}
+		switch tc.name {
+		case "signal":
+			if got.Signature != "su" {
+				t.Fatalf("signal signature: got %q", got.Signature)
+			}
+			d := dbusDecoder{b: got.Body, order: got.order}
+			name, err := d.string()
+			if err != nil {
+				t.Fatal(err)
+			}
+			pid, err := d.uint32()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != "firefox" || pid != 1234 || d.pos != len(got.Body) {
+				t.Errorf("signal body: got %q, %d (%d of %d bytes)", name, pid, d.pos, len(got.Body))
+			}
+		case "error":
+			if msg := got.errorMessage(); msg != "chrome" {
+				t.Errorf("error message: got %q", msg)
+			}
+		case "call":
+			if s, err := got.firstString(); err != nil || s != "firefox" || got.Signature != "s" {
+				t.Errorf("call argument: got %q (%q), %v", s, got.Signature, err)
+			}
+		}
+	}
+}
+
+func TestDBusReadTruncated(t *testing.T) {
+	raw := sendRaw(t, dbusMessage{Type: dbusSignal, Path: dbusPath, Interface: dbusInterface, Member: "Stopped"},
+		"firefox", uint32(1234))
+	for _, n := range []int{0, 10, 16, 20, len(raw) - 1} {
+		if _, err := readRaw(raw[:n]); err == nil {
+			t.Errorf("%d of %d bytes: no error", n, len(raw))
+		} else if n != 0 && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%d of %d bytes: got %+v, wanted ErrUnexpectedEOF", n, len(raw), err)
+		}
+	}
+
+	// huge announced lengths are not allocated up front
+	huge := append([]byte(nil), raw[:16]...)
+	binary.LittleEndian.PutUint32(huge[4:], 1<<26)
+	if _, err := readRaw(huge); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("huge body: got %+v", err)
+	}
+	binary.LittleEndian.PutUint32(huge[4:], 1<<27)
+	binary.LittleEndian.PutUint32(huge[12:], 1<<27)
+	if _, err := readRaw(huge); err == nil {
+		t.Error("too long: no error")
+	}
+
+	// header fields cut in the middle, with consistent lengths
+	bad := append([]byte(nil), raw...)
+	binary.LittleEndian.PutUint32(bad[12:], 3)
+	if _, err := readRaw(bad); err == nil {
+		t.Error("cut header field: no error")
+	}
+}
+
+// FuzzDBusRead checks that read does not panic on arbitrary input,
+// and that the header fields it returns survive a round trip through send.
+func FuzzDBusRead(f *testing.F) {
+	f.Add(sendRaw(f, dbusMessage{Type: dbusMethodCall, Destination: dbusName, Path: dbusPath, Interface: dbusInterface, Member: "Resume"}, "firefox"))
+	f.Add(sendRaw(f, dbusMessage{Type: dbusSignal, Path: dbusPath, Interface: dbusInterface, Member: "Stopped"}, "firefox", uint32(1234)))
+	f.Add(sendRaw(f, dbusMessage{Type: dbusMethodReturn, Destination: ":1.42", ReplySerial: 7}))
+	f.Add(sendRaw(f, dbusMessage{Type: dbusError, Destination: ":1.42", ReplySerial: 3, ErrorName: dbusInterface + ".Error.UnknownProgram"}, "chrome"))
+	f.Add([]byte("B\x01\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00"))
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		msg, err := readRaw(raw)
+		if err != nil {
+			return
+		}
+		if len(msg.Body) > len(raw) {
+			t.Fatalf("body of %d bytes from %d bytes of input", len(msg.Body), len(raw))
+		}
+		_, _ = msg.firstString()
+		_ = msg.errorMessage()
+
+		again, err := readRaw(sendRaw(t, dbusMessage{Type: msg.Type, Flags: msg.Flags,
+			Path: msg.Path, Interface: msg.Interface, Member: msg.Member, ErrorName: msg.ErrorName,
+			Destination: msg.Destination, ReplySerial: msg.ReplySerial}))
+		if err != nil {
+			t.Fatalf("read back %+v: %+v", msg, err)
+		}
+		if again.Type != msg.Type || again.Flags != msg.Flags ||
+			again.Path != msg.Path || again.Interface != msg.Interface || again.Member != msg.Member ||
+			again.ErrorName != msg.ErrorName || again.Destination != msg.Destination ||
+			again.ReplySerial != msg.ReplySerial {
+			t.Errorf("round trip: got %+v, wanted %+v", again, msg)
+		}
+	})
+}
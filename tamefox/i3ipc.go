@@ -0,0 +1,147 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+)
+
+// i3 IPC protocol, see https://i3wm.org/docs/ipc.html
+//
+// Every message is "i3-ipc" + uint32 length + uint32 type + payload,
+// in native byte order (little endian on all the architectures we care about).
+const (
+	ipcMagic        = "i3-ipc"
+	ipcSubscribe    = 2
+	ipcEventWindow  = 0x80000003
+	ipcHeaderLength = len(ipcMagic) + 4 + 4
+	// ipcMaxLength limits the payload length, even a big get_tree reply is far below it.
+	ipcMaxLength = 64 << 20
+)
+
+var ipcByteOrder = binary.LittleEndian
+
+type ipcReader struct {
+	conn net.Conn
+	buf  []byte
+}
+
+// newIPCReader connects to the i3/sway IPC socket and subscribes to the window events.
+func newIPCReader(ctx context.Context) (*ipcReader, error) {
+	path, err := ipcSocketPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("dial %q: %w", path, err)
+	}
+	ir := &ipcReader{conn: conn}
+	if err = ir.write(ipcSubscribe, []byte(`["window"]`)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	typ, payload, err := ir.read()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	var reply struct {
+		Success bool `json:"success"`
+	}
+	if typ != ipcSubscribe {
+		err = fmt.Errorf("subscribe: got reply type %d", typ)
+	} else if err = json.Unmarshal(payload, &reply); err == nil && !reply.Success {
+		err = fmt.Errorf("subscribe: %s", payload)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go func() { <-ctx.Done(); conn.Close() }()
+	return ir, nil
+}
+
+// ipcSocketPath returns the path of the IPC socket from SWAYSOCK, I3SOCK or "i3 --get-socketpath".
+func ipcSocketPath(ctx context.Context) (string, error) {
+	for _, k := range []string{"SWAYSOCK", "I3SOCK"} {
+		if s := os.Getenv(k); s != "" {
+			return s, nil
+		}
+	}
+	b, err := exec.CommandContext(ctx, "i3", "--get-socketpath").Output()
+	if b = bytes.TrimSpace(b); len(b) != 0 {
+		return string(b), nil
+	}
+	if err == nil {
+		err = errors.New("empty socket path")
+	}
+	return "", fmt.Errorf("no SWAYSOCK nor I3SOCK, i3 --get-socketpath: %w", err)
+}
+
+func (ir *ipcReader) write(typ uint32, payload []byte) error {
+	b := make([]byte, ipcHeaderLength, ipcHeaderLength+len(payload))
+	copy(b, ipcMagic)
+	ipcByteOrder.PutUint32(b[len(ipcMagic):], uint32(len(payload)))
+	ipcByteOrder.PutUint32(b[len(ipcMagic)+4:], typ)
+	_, err := ir.conn.Write(append(b, payload...))
+	return err
+}
+
+func (ir *ipcReader) read() (uint32, []byte, error) {
+	if cap(ir.buf) < ipcHeaderLength {
+		ir.buf = make([]byte, ipcHeaderLength, 4096)
+	}
+	hdr := ir.buf[:ipcHeaderLength]
+	if _, err := io.ReadFull(ir.conn, hdr); err != nil {
+		return 0, nil, err
+	}
+	if string(hdr[:len(ipcMagic)]) != ipcMagic {
+		return 0, nil, fmt.Errorf("bad magic %q", hdr[:len(ipcMagic)])
+	}
+	length := ipcByteOrder.Uint32(hdr[len(ipcMagic):])
+	typ := ipcByteOrder.Uint32(hdr[len(ipcMagic)+4:])
+	if length > ipcMaxLength {
+		return 0, nil, fmt.Errorf("message of %d bytes is longer than %d", length, ipcMaxLength)
+	}
+	if cap(ir.buf) < int(length) {
+		ir.buf = make([]byte, length)
+	}
+	payload := ir.buf[:length]
+	_, err := io.ReadFull(ir.conn, payload)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return typ, payload, err
+}
+
+// Next returns the next window event.
+func (ir *ipcReader) Next() (Change, error) {
+	for {
+		typ, payload, err := ir.read()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				err = io.EOF
+			}
+			return Change{}, err
+		}
+		if typ != ipcEventWindow {
+			continue
+		}
+		var change Change
+		err = json.Unmarshal(payload, &change)
+		return change, err
+	}
+}
+
+func (ir *ipcReader) Close() error { return ir.conn.Close() }
@@ -0,0 +1,55 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestIPCRead(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		length  uint32
+		payload string
+		ok      bool
+	}{
+		{"ok", 5, "hello", true},
+		{"empty", 0, "", true},
+		{"truncated", 10, "hello", false},
+		{"huge", 1<<32 - 1, "", false},
+		{"too long", ipcMaxLength + 1, "", false},
+	} {
+		a, b := net.Pipe()
+		go func() {
+			hdr := make([]byte, ipcHeaderLength)
+			copy(hdr, ipcMagic)
+			ipcByteOrder.PutUint32(hdr[len(ipcMagic):], tc.length)
+			ipcByteOrder.PutUint32(hdr[len(ipcMagic)+4:], ipcEventWindow)
+			_, _ = a.Write(append(hdr, tc.payload...))
+			a.Close()
+		}()
+		ir := &ipcReader{conn: b}
+		typ, payload, err := ir.read()
+		b.Close()
+		if !tc.ok {
+			if err == nil {
+				t.Errorf("%s: no error", tc.name)
+			} else if tc.name == "truncated" && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("%s: got %+v, wanted ErrUnexpectedEOF", tc.name, err)
+			}
+			if cap(ir.buf) > ipcMaxLength {
+				t.Errorf("%s: allocated %d bytes", tc.name, cap(ir.buf))
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %+v", tc.name, err)
+		}
+		if typ != ipcEventWindow || string(payload) != tc.payload {
+			t.Errorf("%s: got %x %q", tc.name, typ, payload)
+		}
+	}
+}
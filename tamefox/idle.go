@@ -0,0 +1,52 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// watchIdle calls onIdle after timeout of user inactivity, and onResume on activity after that.
+//
+// It uses swayidle, which uses the compositor's idle-notify protocol.
+func watchIdle(ctx context.Context, timeout time.Duration, onIdle, onResume func()) error {
+	secs := int((timeout + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	cmd := exec.CommandContext(ctx, "swayidle", "-w",
+		"timeout", strconv.Itoa(secs), "echo idle",
+		"resume", "echo resume")
+	pr, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", cmd.Args, err)
+	}
+	go func() {
+		defer cmd.Wait()
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			switch line := scanner.Text(); line {
+			case "idle":
+				logger.Debug("idle", "timeout", timeout)
+				onIdle()
+			case "resume":
+				logger.Debug("active")
+				onResume()
+			default:
+				logger.Debug("swayidle", "line", line)
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			logger.Warn("swayidle", "error", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+
+	"golang.org/x/exp/slog"
+)
+
+// logger is the structured logger - every STOP/CONT is logged with the pid, prog and reason.
+var logger = slog.New(slog.HandlerOptions{Level: slog.LevelWarn}.NewTextHandler(os.Stderr))
+
+// setupLogging sets logger up: format is "text" or "json",
+// toSyslog routes the logs to syslog (the journal), verbose lowers the level to debug
+// (dry-run to info).
+func setupLogging(format string, toSyslog, verbose bool) error {
+	opts := slog.HandlerOptions{Level: slog.LevelWarn}
+	if verbose {
+		opts.Level = slog.LevelDebug
+	} else if dryRun {
+		opts.Level = slog.LevelInfo
+	}
+	var w io.Writer = os.Stderr
+	if toSyslog {
+		sw, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "tamefox")
+		if err != nil {
+			return fmt.Errorf("syslog: %w", err)
+		}
+		w = sw
+		// syslog has its own timestamp
+		opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		}
+	}
+	switch format {
+	case "", "text":
+		logger = slog.New(opts.NewTextHandler(w))
+	case "json":
+		logger = slog.New(opts.NewJSONHandler(w))
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+//go:build darwin
+// +build darwin
+
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSource of the window events.
+	defaultSource = "macos"
+	// defaultACFile is empty, as there is no /sys/class/power_supply on macOS.
+	defaultACFile = ""
+)
+
+// macOSPollInterval is the frequency of querying the frontmost application.
+const macOSPollInterval = 500 * time.Millisecond
+
+// macOSReader polls the frontmost application with lsappinfo (as NSWorkspace sees it),
+// and returns a "focus" Change when it changes.
+//
+// The Container.AppID is the bundle identifier (such as "org.mozilla.firefox"),
+// the WindowProperties.Class is the display name of the application.
+type macOSReader struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	ticker  *time.Ticker
+	lastPID int
+}
+
+func newMacOSReader(ctx context.Context) (*macOSReader, error) {
+	if _, err := exec.LookPath("lsappinfo"); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &macOSReader{ctx: ctx, cancel: cancel, ticker: time.NewTicker(macOSPollInterval)}, nil
+}
+
+func (mr *macOSReader) Next() (Change, error) {
+	for {
+		change, err := frontmostApp(mr.ctx)
+		if err != nil {
+			if mr.ctx.Err() != nil {
+				return change, io.EOF
+			}
+			return change, err
+		}
+		if change.Container.PID != 0 && change.Container.PID != mr.lastPID {
+			mr.lastPID = change.Container.PID
+			return change, nil
+		}
+		select {
+		case <-mr.ctx.Done():
+			return Change{}, io.EOF
+		case <-mr.ticker.C:
+		}
+	}
+}
+
+func (mr *macOSReader) Close() error {
+	mr.cancel()
+	mr.ticker.Stop()
+	return nil
+}
+
+// frontmostApp returns the frontmost application as a "focus" Change.
+func frontmostApp(ctx context.Context) (Change, error) {
+	change := Change{Change: "focus"}
+	asn, err := exec.CommandContext(ctx, "lsappinfo", "front").Output()
+	if err != nil {
+		return change, fmt.Errorf("lsappinfo front: %w", err)
+	}
+	b, err := exec.CommandContext(ctx, "lsappinfo", "info",
+		"-only", "bundleid", "-only", "pid", "-only", "name",
+		strings.TrimSpace(string(asn))).Output()
+	if err != nil {
+		return change, fmt.Errorf("lsappinfo info: %w", err)
+	}
+	// "CFBundleIdentifier"="org.mozilla.firefox"
+	// "pid"=1234
+	// "LSDisplayName"="Firefox"
+	for _, line := range strings.Split(string(b), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		v = strings.Trim(v, `"`)
+		switch strings.Trim(k, `"`) {
+		case "CFBundleIdentifier":
+			change.Container.AppID = v
+		case "pid":
+			change.Container.PID, _ = strconv.Atoi(v)
+		case "LSDisplayName":
+			change.Container.WindowProperties.Class = v
+		}
+	}
+	return change, nil
+}
@@ -0,0 +1,24 @@
+//go:build !darwin
+// +build !darwin
+
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// defaultSource of the window events.
+	defaultSource = "swaymsg"
+	// defaultACFile is the AC online file of the power supply.
+	defaultACFile = "/sys/class/power_supply/AC/online"
+)
+
+type macOSReader struct{ changeReader }
+
+func newMacOSReader(ctx context.Context) (*macOSReader, error) {
+	return nil, errors.New("the macos source is available on macOS only")
+}
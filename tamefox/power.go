@@ -0,0 +1,54 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// batteryGlob matches the capacity (percentage) files of the batteries.
+const batteryGlob = "/sys/class/power_supply/BAT*/capacity"
+
+// onAC reports whether the AC online file (such as /sys/class/power_supply/AC/online) says "1".
+func onAC(fn string) (bool, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(bytes.TrimSpace(b), []byte("1")), nil
+}
+
+// batteryCapacity returns the mean charge percentage of the batteries.
+func batteryCapacity() (int, error) {
+	fns, err := filepath.Glob(batteryGlob)
+	if err != nil {
+		return 0, err
+	}
+	if len(fns) == 0 {
+		return 0, fmt.Errorf("%s: %w", batteryGlob, os.ErrNotExist)
+	}
+	var sum int
+	var errs []error
+	for _, fn := range fns {
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c, err := strconv.Atoi(string(bytes.TrimSpace(b)))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fn, err))
+			continue
+		}
+		sum += c
+	}
+	if n := len(fns) - len(errs); n != 0 {
+		return sum / n, nil
+	}
+	return 0, errors.Join(errs...)
+}
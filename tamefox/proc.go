@@ -0,0 +1,91 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// stopped is the set of the PIDs we have STOPped (or frozen), to be CONTinued on exit.
+var stopped = pidSet{m: make(map[int]struct{})}
+
+type pidSet struct {
+	m  map[int]struct{}
+	mu sync.Mutex
+}
+
+func (ps *pidSet) add(pid int) {
+	ps.mu.Lock()
+	ps.m[pid] = struct{}{}
+	ps.mu.Unlock()
+}
+
+func (ps *pidSet) remove(pid int) {
+	ps.mu.Lock()
+	delete(ps.m, pid)
+	ps.mu.Unlock()
+}
+
+func (ps *pidSet) list() []int {
+	ps.mu.Lock()
+	pids := make([]int, 0, len(ps.m))
+	for pid := range ps.m {
+		pids = append(pids, pid)
+	}
+	ps.mu.Unlock()
+	sort.Ints(pids)
+	return pids
+}
+
+// stopSignal is sent to STOP the processes: SIGSTOP, or SIGTSTP which can be handled.
+var stopSignal = syscall.SIGSTOP
+
+// dryRun makes signal (and freezeCgroup) only log what it would do.
+var dryRun bool
+
+// signal sends sig to pid, and records STOP/CONT in stopped.
+//
+// A process which has already exited results in a (logged at Debug level only) ESRCH error,
+// see isGone.
+func signal(pid int, sig syscall.Signal) error {
+	var err error
+	if dryRun {
+		logger.Info("dry-run", "pid", pid, "signal", sig.String())
+	} else {
+		err = syscall.Kill(pid, sig)
+	}
+	if isGone(err) {
+		logger.Debug("gone", "pid", pid, "signal", sig.String())
+		stopped.remove(pid)
+		return err
+	}
+	if err == nil {
+		switch sig {
+		case syscall.SIGSTOP, syscall.SIGTSTP:
+			stopped.add(pid)
+		case syscall.SIGCONT:
+			stopped.remove(pid)
+		}
+	}
+	return err
+}
+
+// isGone reports whether the error means that the process does not exist (anymore).
+func isGone(err error) bool { return errors.Is(err, syscall.ESRCH) }
+
+// resumeAll CONTinues (thaws) every process we have stopped.
+func resumeAll() {
+	for _, pid := range stopped.list() {
+		if useCgroup {
+			_ = freezeCgroup(pid, false)
+		}
+		logger.Info("CONT", "pid", pid, "reason", "exit")
+		if err := signal(pid, syscall.SIGCONT); err != nil && !isGone(err) {
+			logger.Warn("CONT", "pid", pid, "error", err)
+		}
+		stopped.remove(pid)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build darwin
+// +build darwin
+
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// childrenMap returns the children PIDs of each process, from ps (there is no /proc on macOS).
+func childrenMap() (map[int][]int, error) {
+	b, err := exec.Command("ps", "-axo", "pid=,ppid=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	c := make(map[int][]int)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil || pid == 0 {
+			continue
+		}
+		if ppid, err := strconv.Atoi(fields[1]); err == nil && ppid > 1 {
+			c[ppid] = append(c[ppid], pid)
+		}
+	}
+	return c, scanner.Err()
+}
+
+func getPPid(pid int) (int, error) {
+	b, err := exec.Command("ps", "-o", "ppid=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(bytes.TrimSpace(b)))
+}
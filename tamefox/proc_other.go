@@ -0,0 +1,48 @@
+//go:build !darwin
+// +build !darwin
+
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// childrenMap returns the children PIDs of each process, from /proc.
+func childrenMap() (map[int][]int, error) {
+	dis, _ := os.ReadDir(procRoot)
+	c := make(map[int][]int, len(dis))
+	for _, di := range dis {
+		pid, err := strconv.Atoi(di.Name())
+		if err != nil || pid == 0 {
+			continue
+		}
+		ppid, err := getPPid(pid)
+		if ppid == 1 || ppid == 0 {
+			continue
+		}
+		if err != nil {
+			return c, err
+		}
+		c[ppid] = append(c[ppid], pid)
+	}
+	return c, nil
+}
+
+func getPPid(pid int) (int, error) {
+	b, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "status"))
+	i := bytes.Index(b, []byte("\nPPid:"))
+	if i < 0 {
+		return 0, err
+	}
+	b = b[i+7:]
+	i = bytes.IndexByte(b, '\n')
+	if i >= 0 {
+		b = b[:i]
+	}
+	return strconv.Atoi(string(bytes.TrimSpace(b)))
+}
@@ -3,20 +3,21 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/tgulacsi/go/globalctx"
+	"golang.org/x/exp/slog"
 )
 
 /*
@@ -47,117 +48,374 @@ func main() {
 	}
 }
 
-var self = os.Getpid()
+var (
+	self = os.Getpid()
+
+	// useCgroup is true if we should freeze the cgroup instead of sending signals.
+	useCgroup bool
+)
 
 func Main() error {
-	flagTimeout := flag.Duration("t", 10*time.Second, "timeout for stop")
-	flagProg := flag.String("prog", "firefox", "name of the program")
+	flagTimeout := flag.Duration("t", 10*time.Second, "timeout for stop (inactivity time, with -idle)")
+	flagProg := flag.String("prog", "firefox,firefox-esr", "comma-separated list of the names (app_id, or the last part of a reverse-DNS app_id or bundle id) of the programs, each with an optional timeout: firefox:30s,thunderbird:2s")
 	flagStopDepth := flag.Int("stop-depth", 1, "STOP depth of child tree")
-	flagAC := flag.String("ac", "/sys/class/power_supply/AC/online", "check AC (non-battery) here")
+	flagAC := flag.String("ac", defaultACFile, "check AC (non-battery) here")
+	flagBatteryThreshold := flag.Int("battery-threshold", 0, "on battery, STOP only if the charge is below this percentage (0 to always STOP)")
 	flagVerbose := flag.Bool("v", false, "verbose logging")
+	flagLogFormat := flag.String("log-format", "text", "log format: text or json")
+	flagSyslog := flag.Bool("syslog", false, "log to syslog (the journal)")
+	flagSource := flag.String("source", defaultSource, "source of window events: swaymsg, ipc (i3/sway IPC socket) or macos (the frontmost application, by lsappinfo)")
+	flagMethod := flag.String("method", "signal", "stop method: signal (STOP/CONT) or cgroup (cgroup v2 freezer, falls back to signal)")
+	flag.IntVar(&throttleDuty, "throttle", 0, "throttle instead of STOP: let the program run this percentage of the time")
+	flag.DurationVar(&throttlePeriod, "throttle-period", throttlePeriod, "length of one STOP+CONT throttling cycle")
+	flag.BoolVar(&dryRun, "dry-run", false, "only log the signals that would be sent")
+	flagIdle := flag.Bool("idle", false, "STOP the programs after -t of inactivity (using swayidle), instead of after losing focus")
+	flagNoStopWhilePlaying := flag.Bool("no-stop-while-playing", false, "don't STOP a program while it has a fullscreen or Picture-in-Picture window")
+	flagSignal := flag.String("signal", "STOP", "stop signal: STOP, or TSTP (which can be handled by the program)")
+	flagOnce := flag.Bool("once", false, "exit when the window event subscription ends, instead of resubscribing")
+	flagDBus := flag.Bool("dbus", false, "serve the "+dbusName+" D-Bus interface (Resume, Stop, Status) on the session bus")
+	flagStatus := flag.Bool("status", false, "print the status of the running tamefox (started with -dbus), and exit")
+	flagConfig := flag.String("config", "", "TOML (or JSON) config file, with the flag names as keys; flags override it")
 	flag.Parse()
+	if *flagConfig != "" {
+		if err := loadConfig(flag.CommandLine, *flagConfig); err != nil {
+			return err
+		}
+	}
+	if throttleDuty < 0 || throttleDuty >= 100 {
+		return fmt.Errorf("throttle duty must be between 0 and 99, got %d", throttleDuty)
+	}
 
-	if !*flagVerbose {
-		log.SetOutput(io.Discard)
+	switch strings.TrimPrefix(strings.ToUpper(*flagSignal), "SIG") {
+	case "STOP":
+		stopSignal = syscall.SIGSTOP
+	case "TSTP":
+		stopSignal = syscall.SIGTSTP
+	default:
+		return fmt.Errorf("unknown stop signal %q", *flagSignal)
+	}
+
+	switch *flagMethod {
+	case "signal":
+	case "cgroup":
+		useCgroup = true
+	default:
+		return fmt.Errorf("unknown method %q", *flagMethod)
+	}
+
+	if err := setupLogging(*flagLogFormat, *flagSyslog, *flagVerbose); err != nil {
+		return err
 	}
 
 	ctx, cancel := globalctx.Wrap(context.Background())
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "swaymsg", "-m", "-t", "subscribe", "[\"window\"]")
-	pr, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
+	if *flagStatus {
+		status, err := dbusStatus(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Print(status)
+		return nil
 	}
-	if err = cmd.Start(); err != nil {
+	var subscribe func() (changeReader, error)
+	switch *flagSource {
+	case "ipc":
+		subscribe = func() (changeReader, error) { return newIPCReader(ctx) }
+	case "swaymsg":
+		subscribe = func() (changeReader, error) { return newSwaymsgReader(ctx) }
+	case "macos":
+		subscribe = func() (changeReader, error) { return newMacOSReader(ctx) }
+	default:
+		return fmt.Errorf("unknown source %q", *flagSource)
+	}
+	changes, err := subscribe()
+	if err != nil {
 		return err
 	}
+	defer func() {
+		if changes != nil {
+			changes.Close()
+		}
+	}()
 
 	timeout := *flagTimeout
-	var timer *time.Timer
-	stopTimer := func() {
-		if timer != nil && !timer.Stop() {
-			select {
-			case <-timer.C:
-			default:
-			}
+	managed, err := parseApps(*flagProg, *flagStopDepth)
+	if err != nil {
+		return err
+	}
+	if *flagDBus {
+		if bus, err = newDBusService(ctx, managed); err != nil {
+			return err
 		}
 	}
-	var ff int
+	// On exit (also on SIGINT/SIGTERM, as that cancels ctx, thus ends the changes),
+	// CONTinue all the managed apps, and every other process we've stopped.
+	defer resumeAll()
 	defer func() {
-		if ff != 0 {
-			kill(ff, false, 999)
+		for _, a := range managed {
+			a.resume("exit")
+			stopped, total := a.stoppedFor()
+			logger.Info(summary(a.Name, stopped, total), "prog", a.Name, "stopped", stopped, "total", total)
 		}
 	}()
-	dec := json.NewDecoder(pr)
-	for dec.More() {
-		var change Change
-		if err = dec.Decode(&change); err != nil {
+	// skipStop reports whether the STOP should be skipped, because of the power source.
+	skipStop := func() (bool, error) {
+		if *flagAC != "" {
+			ok, err := onAC(*flagAC)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				logger.Info("skip STOP", "reason", "on-AC")
+				return true, nil
+			}
+		}
+		if *flagBatteryThreshold > 0 {
+			if c, err := batteryCapacity(); err != nil {
+				logger.Warn("battery capacity", "error", err)
+			} else if c >= *flagBatteryThreshold {
+				logger.Info("skip STOP", "reason", "battery-above-threshold", "capacity", c, "threshold", *flagBatteryThreshold)
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if *flagIdle {
+		if err := watchIdle(ctx, timeout,
+			func() {
+				if skip, err := skipStop(); err != nil {
+					logger.Warn("check power", "error", err)
+				} else if !skip {
+					for _, a := range managed {
+						a.stopFor("idle")
+					}
+				}
+			},
+			func() {
+				for _, a := range managed {
+					a.resume("active")
+				}
+			},
+		); err != nil {
 			return err
 		}
-		log.Println(change)
-		if change.Change != "focus" {
+	}
+	// playing are the fullscreen and Picture-in-Picture windows (container IDs) of the managed apps.
+	playing := make(map[int64]*app)
+	var lastFocused *app
+	backoff := minBackoff
+	for {
+		change, err := changes.Next()
+		if err != nil {
+			if *flagOnce || ctx.Err() != nil {
+				if errors.Is(err, io.EOF) || ctx.Err() != nil {
+					break
+				}
+				return err
+			}
+			// The compositor has been restarted (or reloaded):
+			// CONTinue everything, as we may not see their focus again.
+			logger.Warn("subscription ended, resubscribing", "error", err, "backoff", backoff)
+			for _, a := range managed {
+				a.resume("disconnect")
+			}
+			resumeAll()
+			changes.Close()
+			if changes, err = resubscribe(ctx, subscribe, &backoff); err != nil {
+				break
+			}
 			continue
 		}
-		if strings.EqualFold(change.Container.AppID, *flagProg) ||
-			(*flagProg == "firefox" &&
-				(strings.EqualFold(change.Container.AppID, "firefox") ||
-					strings.EqualFold(change.Container.AppID, "firefox-esr"))) {
-			ff = change.Container.PID
-			kill(ff, false, 999)
-			stopTimer()
+		backoff = minBackoff
+		logger.Debug("event", "change", change.Change, "app_id", change.Container.AppID, "pid", change.Container.PID)
+		if change.Container.AppID == "" {
+			change.Container.AppID = change.Container.WindowProperties.Class
+		}
+		if *flagNoStopWhilePlaying {
+			if a := managed.find(change.Container.AppID); a != nil {
+				if change.Change != "close" && change.Container.isPlaying() {
+					playing[change.Container.ID] = a
+				} else {
+					delete(playing, change.Container.ID)
+				}
+				var exempt bool
+				for _, p := range playing {
+					if exempt = p == a; exempt {
+						break
+					}
+				}
+				if a.setExempt(exempt) && !exempt && !*flagIdle && a != lastFocused {
+					// stopped playing in the background
+					if skip, err := skipStop(); err == nil && !skip {
+						a.scheduleStop(timeout)
+					}
+				}
+			}
+		}
+		if change.Change != "focus" {
 			continue
 		}
-		kill(change.Container.PID, false, 0)
+		focused := managed.find(change.Container.AppID)
+		lastFocused = focused
+		if focused != nil {
+			focused.focus(change.Container.PID)
+		} else {
+			kill(logger.With("prog", change.Container.AppID, "reason", "focus"), change.Container.PID, false, 0, false)
+		}
 
-		if *flagAC != "" {
-			b, err := os.ReadFile(*flagAC)
-			if err != nil {
-				return err
-			}
-			b = bytes.TrimSpace(b)
-			if bytes.Equal(bytes.TrimSpace(b), []byte("1")) {
-				log.Println("on AC, skip STOP")
-				continue
-			}
+		if *flagIdle {
+			// STOPped on idle only
+			continue
 		}
-		if timer == nil {
-			timer = time.AfterFunc(timeout, func() {
-				kill(ff, true, *flagStopDepth)
-			})
+		if skip, err := skipStop(); err != nil {
+			return err
+		} else if skip {
 			continue
 		}
-		stopTimer()
-		timer.Reset(timeout)
+		for _, a := range managed {
+			if a != focused {
+				a.scheduleStop(timeout)
+			}
+		}
 	}
 	return nil
 }
 
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// resubscribe calls subscribe till it succeeds, waiting between the tries with exponential backoff.
+// It returns an error only when ctx is done.
+func resubscribe(ctx context.Context, subscribe func() (changeReader, error), backoff *time.Duration) (changeReader, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(*backoff):
+		}
+		if *backoff *= 2; *backoff > maxBackoff {
+			*backoff = maxBackoff
+		}
+		changes, err := subscribe()
+		if err == nil {
+			logger.Info("resubscribed")
+			return changes, nil
+		}
+		logger.Warn("subscribe", "error", err, "backoff", *backoff)
+	}
+}
+
 type Change struct {
 	Change    string    `json:"change"`
 	Container Container `json:"container"`
 }
 type Container struct {
 	AppID string `json:"app_id"`
-	PID   int    `json:"pid"`
+	// Name is the title of the window.
+	Name string `json:"name"`
+	ID   int64  `json:"id"`
+	PID  int    `json:"pid"`
+	// FullscreenMode is 0 for a normal window, 1 for fullscreen on the output, 2 for global fullscreen.
+	FullscreenMode int `json:"fullscreen_mode"`
+	// WindowProperties is filled by i3 (X11) for non-Wayland windows.
+	WindowProperties struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+}
+
+// isPlaying reports whether the window is fullscreen or a Picture-in-Picture video.
+func (c Container) isPlaying() bool {
+	return c.FullscreenMode != 0 || strings.EqualFold(c.Name, "Picture-in-Picture")
+}
+
+// changeReader returns the window changes one-by-one.
+type changeReader interface {
+	Next() (Change, error)
+	Close() error
+}
+
+type swaymsgReader struct {
+	cmd *exec.Cmd
+	dec *json.Decoder
+}
+
+// newSwaymsgReader subscribes to the window events with swaymsg.
+func newSwaymsgReader(ctx context.Context) (*swaymsgReader, error) {
+	cmd := exec.CommandContext(ctx, "swaymsg", "-m", "-t", "subscribe", "[\"window\"]")
+	pr, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &swaymsgReader{cmd: cmd, dec: json.NewDecoder(pr)}, nil
+}
+
+func (sr *swaymsgReader) Next() (Change, error) {
+	var change Change
+	if !sr.dec.More() {
+		return change, io.EOF
+	}
+	err := sr.dec.Decode(&change)
+	return change, err
+}
+
+func (sr *swaymsgReader) Close() error {
+	if sr.cmd.Process != nil {
+		_ = sr.cmd.Process.Kill()
+	}
+	return sr.cmd.Wait()
 }
 
-func kill(pid int, stop bool, depth int) error {
+// kill STOPs or CONTinues pid and its children down to depth.
+//
+// The children which have exited meanwhile are skipped,
+// but if pid itself has exited, an ESRCH error is returned (see isGone).
+// If quiet, the signals are logged at Debug level only.
+func kill(lgr *slog.Logger, pid int, stop bool, depth int, quiet bool) error {
 	if pid == 0 || pid == self {
 		return nil
 	}
+	level := slog.LevelInfo
+	if quiet {
+		level = slog.LevelDebug
+	}
+	if useCgroup {
+		err := freezeCgroup(pid, stop)
+		if err == nil {
+			if stop {
+				lgr.Log(level, "FREEZE", "pid", pid)
+				stopped.add(pid)
+			} else {
+				lgr.Log(level, "THAW", "pid", pid)
+				stopped.remove(pid)
+			}
+			return nil
+		}
+		lgr.Debug("freeze", "pid", pid, "error", err)
+	}
 	var firstErr error
 	if stop {
-		const sig = syscall.SIGSTOP
-		log.Println("STOP", pid)
-		firstErr = syscall.Kill(pid, sig)
-		if err := ckill(pid, sig, nil, depth); err != nil && firstErr == nil {
+		sig := stopSignal
+		lgr.Log(level, "STOP", "pid", pid, "depth", depth, "signal", sig.String())
+		if firstErr = signal(pid, sig); isGone(firstErr) {
+			return firstErr
+		}
+		if err := ckill(pid, sig, nil, depth); err != nil && !isGone(err) && firstErr == nil {
 			firstErr = err
 		}
 	} else {
-		log.Println("CONT", pid)
+		lgr.Log(level, "CONT", "pid", pid, "depth", depth)
 		const sig = syscall.SIGCONT
-		firstErr = ckill(pid, sig, nil, depth)
-		if err := syscall.Kill(pid, sig); err != nil && firstErr != nil {
+		if firstErr = ckill(pid, sig, nil, depth); isGone(firstErr) {
+			firstErr = nil
+		}
+		if err := signal(pid, sig); isGone(err) {
+			return err
+		} else if err != nil && firstErr == nil {
 			firstErr = err
 		}
 	}
@@ -166,51 +424,43 @@ func kill(pid int, stop bool, depth int) error {
 
 func ckill(ppid int, sig syscall.Signal, c map[int][]int, depth int) error {
 	if depth == 0 {
-		return syscall.Kill(ppid, sig)
+		return signal(ppid, sig)
 	}
-	dis, _ := os.ReadDir("/proc")
 	if c == nil {
-		c = make(map[int][]int, len(dis))
-		for _, di := range dis {
-			pid, err := strconv.Atoi(di.Name())
-			if err != nil || pid == 0 {
-				continue
-			}
-			ppid, err := getPPid(pid)
-			if ppid == 1 || ppid == 0 {
-				continue
-			}
-			if err != nil {
-				return err
-			}
-			c[ppid] = append(c[ppid], pid)
+		var err error
+		if c, err = childrenMap(); err != nil {
+			return err
 		}
 	}
 	var firstErr error
 	for _, pid := range c[ppid] {
-		if err := ckill(pid, sig, c, depth-1); err != nil && firstErr == nil {
+		if !isChildOf(pid, ppid) {
+			logger.Debug("skip: parent changed", "pid", pid, "ppid", ppid)
+			continue
+		}
+		if err := ckill(pid, sig, c, depth-1); err != nil && !isGone(err) && firstErr == nil {
 			firstErr = err
 		}
 		if pid == 0 || pid == self {
 			continue
 		}
-		if err := syscall.Kill(pid, sig); err != nil && firstErr == nil {
+		// the PID may have been reused while we were busy with its children
+		if !isChildOf(pid, ppid) {
+			logger.Debug("skip: parent changed", "pid", pid, "ppid", ppid)
+			continue
+		}
+		if err := signal(pid, sig); err != nil && !isGone(err) && firstErr == nil {
 			firstErr = err
 		}
 	}
 	return firstErr
 }
 
-func getPPid(pid int) (int, error) {
-	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/status")
-	i := bytes.Index(b, []byte("\nPPid:"))
-	if i < 0 {
-		return 0, err
-	}
-	b = b[i+7:]
-	i = bytes.IndexByte(b, '\n')
-	if i >= 0 {
-		b = b[:i]
-	}
-	return strconv.Atoi(string(bytes.TrimSpace(b)))
+// isChildOf re-reads the parent of pid, and reports whether it is still ppid.
+//
+// The children map of ckill is built once, so between that and the signaling,
+// the PID may have been reused by an unrelated process.
+func isChildOf(pid, ppid int) bool {
+	p, err := getPPid(pid)
+	return err == nil && p == ppid
 }
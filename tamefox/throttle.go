@@ -0,0 +1,68 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+var (
+	// throttleDuty is the percentage of time a throttled app may run, 0 means a hard STOP.
+	throttleDuty int
+	// throttlePeriod is the length of one STOP+CONT cycle.
+	throttlePeriod = 500 * time.Millisecond
+)
+
+// throttler cycles STOP/CONT on a process tree.
+type throttler struct {
+	done, finished chan struct{}
+}
+
+// startThrottle starts cycling STOP/CONT on pid (and its children down to depth),
+// letting it run duty percent of the period.
+//
+// Only the start and the end of the throttling is logged at Info level,
+// the signals of each cycle are logged at Debug level.
+func startThrottle(lgr *slog.Logger, pid, depth, duty int, period time.Duration) *throttler {
+	t := &throttler{done: make(chan struct{}), finished: make(chan struct{})}
+	run := period * time.Duration(duty) / 100
+	pause := period - run
+	go func() {
+		defer close(t.finished)
+		lgr.Info("throttle start", "pid", pid, "depth", depth, "duty", duty, "period", period)
+		var cycles int
+		reason := "stopped"
+		defer func() { lgr.Info("throttle end", "pid", pid, "reason", reason, "cycles", cycles) }()
+		for {
+			if err := kill(lgr, pid, true, depth, true); isGone(err) {
+				reason = "exited"
+				return
+			}
+			select {
+			case <-t.done:
+				return
+			case <-time.After(pause):
+			}
+			if err := kill(lgr, pid, false, depth, true); isGone(err) {
+				reason = "exited"
+				return
+			}
+			cycles++
+			select {
+			case <-t.done:
+				return
+			case <-time.After(run):
+			}
+		}
+	}()
+	return t
+}
+
+// Stop the cycling, and wait for the last signal to be sent.
+// The process may be left STOPped!
+func (t *throttler) Stop() {
+	close(t.done)
+	<-t.finished
+}
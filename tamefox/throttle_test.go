@@ -0,0 +1,43 @@
+// Copyright 2023 Tamás Gulácsi. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+func TestThrottleLogging(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer func() { _ = cmd.Process.Kill(); _ = cmd.Wait() }()
+
+	var buf bytes.Buffer
+	lgr := slog.New(slog.HandlerOptions{Level: slog.LevelInfo}.NewTextHandler(&buf))
+	th := startThrottle(lgr, cmd.Process.Pid, 0, 50, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	th.Stop()
+	_ = syscall.Kill(cmd.Process.Pid, syscall.SIGCONT)
+
+	logs := buf.String()
+	t.Log(logs)
+	if n := strings.Count(logs, "throttle start"); n != 1 {
+		t.Errorf("got %d starts, wanted 1", n)
+	}
+	if n := strings.Count(logs, "throttle end"); n != 1 {
+		t.Errorf("got %d ends, wanted 1", n)
+	}
+	for _, msg := range []string{"msg=STOP", "msg=CONT", "msg=FREEZE", "msg=THAW"} {
+		if strings.Contains(logs, msg) {
+			t.Errorf("%s logged at Info level", msg)
+		}
+	}
+}
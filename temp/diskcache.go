@@ -0,0 +1,201 @@
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskCache is a size-bounded, on-disk cache of files, keyed by (content) hash:
+// when the total size exceeds the limit, the least recently used files are removed.
+//
+// The files of the directory are taken over by NewDiskCache, so the cache survives restarts.
+// It is safe for concurrent use, but the directory must not be shared between DiskCaches.
+type DiskCache struct {
+	entries map[string]*list.Element
+	lru     *list.List // of *diskEntry, the most recently used at the front
+	dir     string
+	maxSize int64
+	size    int64
+	mu      sync.Mutex
+}
+
+type diskEntry struct {
+	name string
+	size int64
+}
+
+// tempPrefix marks the files being written by Put.
+const tempPrefix = ".put-"
+
+// NewDiskCache returns a DiskCache storing at most maxSize bytes in dir (created if needed).
+func NewDiskCache(dir string, maxSize int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	dis, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type fileInfo struct {
+		modTime time.Time
+		diskEntry
+	}
+	files := make([]fileInfo, 0, len(dis))
+	for _, di := range dis {
+		if !di.Type().IsRegular() {
+			continue
+		}
+		if strings.HasPrefix(di.Name(), tempPrefix) {
+			// left over by an interrupted Put
+			_ = os.Remove(filepath.Join(dir, di.Name()))
+			continue
+		}
+		fi, err := di.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, fileInfo{modTime: fi.ModTime(), diskEntry: diskEntry{name: di.Name(), size: fi.Size()}})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	c := &DiskCache{dir: dir, maxSize: maxSize, lru: list.New(), entries: make(map[string]*list.Element, len(files))}
+	for _, f := range files {
+		e := f.diskEntry
+		c.entries[e.name] = c.lru.PushFront(&e)
+		c.size += e.size
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// Get returns the contents of the key, and whether it was found.
+func (c *DiskCache) Get(key string) (io.ReadCloser, bool) {
+	name := c.fileName(key)
+	c.mu.Lock()
+	elt := c.entries[name]
+	if elt != nil {
+		c.lru.MoveToFront(elt)
+	}
+	c.mu.Unlock()
+	if elt == nil {
+		return nil, false
+	}
+	path := filepath.Join(c.dir, name)
+	fh, err := os.Open(path)
+	if err != nil {
+		// removed behind our back
+		c.mu.Lock()
+		if c.entries[name] == elt {
+			c.remove(elt)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	// keep the order for NewDiskCache
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return fh, true
+}
+
+// Put stores the contents of r under the key, evicting the least recently used files as needed.
+//
+// Contents larger than the limit are not stored.
+func (c *DiskCache) Put(key string, r io.Reader) error {
+	fh, err := os.CreateTemp(c.dir, tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	size, err := io.Copy(fh, r)
+	if closeErr := fh.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err == nil && size > c.maxSize {
+		err = errTooBig
+	}
+	if err != nil {
+		_ = os.Remove(fh.Name())
+		if err == errTooBig {
+			return nil
+		}
+		return fmt.Errorf("write %q: %w", key, err)
+	}
+	name := c.fileName(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = os.Rename(fh.Name(), filepath.Join(c.dir, name)); err != nil {
+		_ = os.Remove(fh.Name())
+		return err
+	}
+	if elt := c.entries[name]; elt != nil {
+		e := elt.Value.(*diskEntry)
+		c.size += size - e.size
+		e.size = size
+		c.lru.MoveToFront(elt)
+	} else {
+		c.entries[name] = c.lru.PushFront(&diskEntry{name: name, size: size})
+		c.size += size
+	}
+	c.evict()
+	return nil
+}
+
+// Size returns the total size of the stored files.
+func (c *DiskCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+var errTooBig = errors.New("too big")
+
+// fileName returns the file name for the key: the key may be any string (such as a base64 hash).
+func (c *DiskCache) fileName(key string) string {
+	hsh := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hsh[:])
+}
+
+// evict removes the least recently used files till the size is under the limit - must be called with c.mu held.
+func (c *DiskCache) evict() {
+	for c.size > c.maxSize {
+		elt := c.lru.Back()
+		if elt == nil {
+			return
+		}
+		c.remove(elt)
+	}
+}
+
+// remove the entry and its file - must be called with c.mu held.
+func (c *DiskCache) remove(elt *list.Element) {
+	e := c.lru.Remove(elt).(*diskEntry)
+	delete(c.entries, e.name)
+	c.size -= e.size
+	_ = os.Remove(filepath.Join(c.dir, e.name))
+}
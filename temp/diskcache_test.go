@@ -0,0 +1,79 @@
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(key string) string {
+		t.Helper()
+		rc, ok := c.Get(key)
+		if !ok {
+			return ""
+		}
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	for _, kv := range [][2]string{{"a/b", "aaaa"}, {"b", "bbbb"}, {"too big", "0123456789A"}} {
+		if err := c.Put(kv[0], strings.NewReader(kv[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := get("a/b"); got != "aaaa" {
+		t.Errorf("a/b: got %q", got)
+	}
+	if got := get("too big"); got != "" {
+		t.Errorf("too big: got %q", got)
+	}
+	// "b" is the least recently used
+	if err := c.Put("c", strings.NewReader("cccc")); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("b"); got != "" {
+		t.Errorf("b: got %q, wanted evicted", got)
+	}
+	if got := get("c"); got != "cccc" {
+		t.Errorf("c: got %q", got)
+	}
+	if size := c.Size(); size != 8 {
+		t.Errorf("size: got %d, wanted 8", size)
+	}
+
+	// reopen
+	if c, err = NewDiskCache(dir, 6); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("c"); got != "cccc" {
+		t.Errorf("reopened c: got %q", got)
+	}
+	if got := get("a/b"); got != "" {
+		t.Errorf("reopened a/b: got %q, wanted evicted", got)
+	}
+}
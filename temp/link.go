@@ -19,13 +19,19 @@ limitations under the License.
 package temp
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
 )
 
+// ErrReflinkUnsupported is returned by Reflink when the platform or the filesystem does not support it.
+var ErrReflinkUnsupported = errors.New("reflink is not supported")
+
 // LinkAlreadyExists checks the error and returns whether this is about
 // a link already exists or not
 func LinkAlreadyExists(err error) bool {
@@ -59,3 +65,60 @@ func copyFile(src, dst string) error {
 	}
 	return nil
 }
+
+// WriteFileAtomic writes data to a temp file in the same directory as path,
+// syncs it, renames it over path, then syncs the directory - so path is either
+// the old or the new content, even after a crash.
+//
+// The file is created with perm (before the umask), just as os.WriteFile does.
+// The temp file is removed on error.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	fh, err := createTemp(dir, "."+base+".", ".tmp", perm)
+	if err != nil {
+		return err
+	}
+	tmp := fh.Name()
+	defer func() {
+		if err != nil {
+			_ = fh.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+	if _, err = fh.Write(data); err != nil {
+		return fmt.Errorf("write %q: %w", tmp, err)
+	}
+	if err = fh.Sync(); err != nil {
+		return fmt.Errorf("sync %q: %w", tmp, err)
+	}
+	if err = fh.Close(); err != nil {
+		return fmt.Errorf("close %q: %w", tmp, err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if err := syncDir(dir); err != nil {
+		return fmt.Errorf("sync %q: %w", dir, err)
+	}
+	return nil
+}
+
+// createTemp is like os.CreateTemp(dir, prefix+"*"+suffix), but creates the file with perm.
+func createTemp(dir, prefix, suffix string, perm os.FileMode) (*os.File, error) {
+	var b [8]byte
+	for try := 0; try < 10; try++ {
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, err
+		}
+		fh, err := os.OpenFile(
+			filepath.Join(dir, prefix+hex.EncodeToString(b[:])+suffix),
+			os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if err == nil || !errors.Is(err, fs.ErrExist) {
+			return fh, err
+		}
+	}
+	return nil, &fs.PathError{Op: "createtemp", Path: filepath.Join(dir, prefix+"*"+suffix), Err: fs.ErrExist}
+}
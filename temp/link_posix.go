@@ -22,18 +22,50 @@ limitations under the License.
 package temp
 
 import (
+	"errors"
 	"os"
 	"runtime"
 	"syscall"
 )
 
-// LinkOrCopy links src to dst if possible; fails back to copying
+// LinkOrCopy reflinks src to dst if possible (Linux only);
+// then tries hardlinking, and fails back to copying
 func LinkOrCopy(src, dst string) error {
-	err := os.Link(src, dst)
-	if le, ok := err.(*os.LinkError); ok && le.Op == "link" && le.Err == syscall.Errno(0x26) && runtime.GOOS == "linux" {
-		// Whatever 0x26 is, it's returned by Linux when the underlying
-		// filesystem (e.g. exfat) doesn't support link.
+	err := Reflink(src, dst)
+	if err == nil || !errors.Is(err, ErrReflinkUnsupported) {
+		return err
+	}
+	err = osLink(src, dst)
+	var le *os.LinkError
+	if !errors.As(err, &le) || le.Op != "link" {
+		return err
+	}
+	switch le.Err {
+	case syscall.EXDEV, syscall.EPERM:
+		// EXDEV: src and dst are on different devices;
+		// EPERM: the filesystem does not support hardlinks (e.g. vfat).
 		return copyFile(src, dst)
+	case syscall.Errno(0x26):
+		if runtime.GOOS == "linux" {
+			// Whatever 0x26 is, it's returned by Linux when the underlying
+			// filesystem (e.g. exfat) doesn't support link.
+			return copyFile(src, dst)
+		}
+	}
+	return err
+}
+
+var osLink = os.Link
+
+// syncDir fsyncs the directory, to make a rename in it durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); closeErr != nil && err == nil {
+		err = closeErr
 	}
 	return err
 }
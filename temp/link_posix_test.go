@@ -0,0 +1,77 @@
+//go:build !windows
+// +build !windows
+
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestLinkOrCopyFallback(t *testing.T) {
+	defer func(f func(string, string) error) { osLink = f }(osLink)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, []byte("data"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	for _, errno := range []syscall.Errno{syscall.EXDEV, syscall.EPERM} {
+		var called bool
+		osLink = func(oldname, newname string) error {
+			called = true
+			return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: errno}
+		}
+		dst := filepath.Join(dir, errno.Error())
+		if err := LinkOrCopy(src, dst); err != nil {
+			t.Fatalf("%v: %+v", errno, err)
+		}
+		if !called {
+			t.Skip("reflinked")
+		}
+		if b, err := os.ReadFile(dst); err != nil {
+			t.Fatal(err)
+		} else if string(b) != "data" {
+			t.Errorf("%v: got %q", errno, b)
+		}
+	}
+
+	osLink = func(oldname, newname string) error {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: syscall.EACCES}
+	}
+	if err := LinkOrCopy(src, filepath.Join(dir, "eacces")); err == nil {
+		t.Error("wanted error for EACCES")
+	}
+}
+
+func TestWriteFileAtomicUmask(t *testing.T) {
+	defer syscall.Umask(syscall.Umask(027))
+	path := filepath.Join(t.TempDir(), "file")
+	if err := WriteFileAtomic(path, []byte("data"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fi.Mode().Perm(); got != 0640 {
+		t.Errorf("got %o, wanted 0640", got)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	for _, want := range []string{"first", "second, longer", "3"} {
+		if err := WriteFileAtomic(path, []byte(want), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := os.ReadFile(path); err != nil {
+			t.Fatal(err)
+		} else if string(b) != want {
+			t.Errorf("got %q, wanted %q", b, want)
+		}
+	}
+	assertOnly := func(names ...string) {
+		t.Helper()
+		des, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(des) != len(names) {
+			t.Errorf("got %v, wanted %v", des, names)
+		}
+		for i, de := range des {
+			if i < len(names) && de.Name() != names[i] {
+				t.Errorf("got %q, wanted %q", de.Name(), names[i])
+			}
+		}
+	}
+	assertOnly("file")
+
+	// renaming over a non-empty directory fails, the temp file is removed
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(filepath.Join(sub, "x"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(sub, []byte("data"), 0600); err == nil {
+		t.Error("no error for a directory")
+	}
+	assertOnly("file", "sub")
+
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "file"), nil, 0600); err == nil {
+		t.Error("no error for a missing directory")
+	}
+}
+
+func TestReflink(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	if err := os.WriteFile(src, []byte("data"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	err := Reflink(src, dst)
+	if err != nil {
+		if !errors.Is(err, ErrReflinkUnsupported) {
+			t.Fatal(err)
+		}
+		t.Log(err)
+		if _, statErr := os.Stat(dst); !errors.Is(statErr, os.ErrNotExist) {
+			t.Errorf("dst is left behind: %v", statErr)
+		}
+		// LinkOrCopy falls back to linking or copying
+		if err = LinkOrCopy(src, dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b, err := os.ReadFile(dst); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "data" {
+		t.Errorf("got %q", b)
+	}
+
+	// an existing dst is not overwritten
+	if err = os.WriteFile(dst, []byte("other"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err = Reflink(src, dst); err == nil {
+		t.Error("no error for existing dst")
+	}
+	if b, _ := os.ReadFile(dst); string(b) != "other" {
+		t.Errorf("dst is changed to %q", b)
+	}
+	if err = Reflink(filepath.Join(dir, "missing"), filepath.Join(dir, "dst2")); err == nil {
+		t.Error("no error for missing src")
+	}
+}
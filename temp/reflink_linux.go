@@ -0,0 +1,62 @@
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// FICLONE is _IOW(0x94, 9, int)
+const ficlone = 0x40049409
+
+// Reflink creates dst as a copy-on-write clone of src (FICLONE ioctl),
+// which is cheap as a hardlink, but the two files are independent.
+//
+// dst must not exist. Returns ErrReflinkUnsupported if the filesystem
+// (or the pair of them) does not support reflinks.
+func Reflink(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	fi, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dstFile.Fd(), ficlone, srcFile.Fd())
+	if errno != 0 {
+		dstFile.Close()
+		os.Remove(dst)
+		switch errno {
+		case syscall.EOPNOTSUPP, syscall.ENOTTY, syscall.EXDEV, syscall.EINVAL, syscall.EBADF, syscall.EPERM:
+			return fmt.Errorf("reflink %q to %q: %w: %w", src, dst, ErrReflinkUnsupported, errno)
+		}
+		return fmt.Errorf("reflink %q to %q: %w", src, dst, errno)
+	}
+	if err = dstFile.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecureFile creates a new temp file for secrets, and opens it for reading and writing.
+//
+// The pattern is used just as in os.CreateTemp: the last "*" is replaced by a random string.
+//
+// Threat model: other local users (or processes of them) on a shared machine.
+// The file is
+//   - created in a fresh 0700 directory (os.MkdirTemp) under the user's runtime dir
+//     ($XDG_RUNTIME_DIR, falling back to os.TempDir), so others can't list, read or replace it;
+//   - created with 0600 at creation time, so there is no window where it is readable by others;
+//   - opened with O_EXCL|O_NOFOLLOW, so a planted symlink (or existing file) makes it fail,
+//     instead of writing the secret to the attacker's chosen destination.
+//
+// It does not protect against the same user or root, nor against swap.
+// Close removes the file and its directory, unless Keep is called.
+func SecureFile(pattern string) (*File, error) {
+	if strings.ContainsRune(pattern, os.PathSeparator) {
+		return nil, errors.New("pattern contains path separator")
+	}
+	base := os.Getenv("XDG_RUNTIME_DIR")
+	if base == "" {
+		base = os.TempDir()
+	}
+	dir, err := os.MkdirTemp(base, "secure-")
+	if err != nil {
+		return nil, err
+	}
+	prefix, suffix := pattern, ""
+	if i := strings.LastIndexByte(pattern, '*'); i >= 0 {
+		prefix, suffix = pattern[:i], pattern[i+1:]
+	}
+	var b [8]byte
+	if _, err = rand.Read(b[:]); err != nil {
+		_ = os.Remove(dir)
+		return nil, err
+	}
+	// the directory is fresh, so the name cannot exist - unless planted
+	fh, err := os.OpenFile(
+		filepath.Join(dir, prefix+hex.EncodeToString(b[:])+suffix),
+		os.O_RDWR|os.O_CREATE|os.O_EXCL|oNoFollow, 0600)
+	if err != nil {
+		_ = os.Remove(dir)
+		return nil, err
+	}
+	return &File{File: fh, dir: dir}, nil
+}
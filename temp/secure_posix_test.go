@@ -0,0 +1,78 @@
+//go:build !windows
+// +build !windows
+
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecureFile(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", base)
+	if _, err := SecureFile("a/b*"); err == nil {
+		t.Error("no error for a pattern with separator")
+	}
+
+	fh, err := SecureFile("secret-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := fh.Name()
+	dir := filepath.Dir(name)
+	if filepath.Dir(dir) != base {
+		t.Errorf("%q is not under %q", name, base)
+	}
+	if b := filepath.Base(name); len(b) <= len("secret-.txt") || b[:7] != "secret-" || filepath.Ext(b) != ".txt" {
+		t.Errorf("bad name %q", b)
+	}
+	for _, tc := range []struct {
+		path string
+		mode os.FileMode
+	}{{name, 0600}, {dir, 0700 | os.ModeDir}} {
+		if fi, err := os.Stat(tc.path); err != nil {
+			t.Fatal(err)
+		} else if got := fi.Mode(); got != tc.mode {
+			t.Errorf("%q: got %v, wanted %v", tc.path, got, tc.mode)
+		}
+	}
+	if _, err = fh.WriteString("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err = fh.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if des, _ := os.ReadDir(base); len(des) != 0 {
+		t.Errorf("not cleaned up: %v", des)
+	}
+
+	// Keep leaves both
+	if fh, err = SecureFile("kept"); err != nil {
+		t.Fatal(err)
+	}
+	fh.Keep()
+	if err = fh.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(fh.Name()); err != nil {
+		t.Errorf("kept file: %+v", err)
+	}
+}
@@ -20,9 +20,12 @@ Package temp contains some temporary file -related functions
 package temp
 
 import (
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"strings"
+	"sync"
 )
 
 // ReaderToFile copies the reader to a temp file and returns its name or error
@@ -66,3 +69,47 @@ func BaseName(fileName string) string {
 	}
 	return fileName
 }
+
+// File is a temp file which is removed when closed - unless Keep is called.
+type File struct {
+	*os.File
+	// dir is removed with the file, if not empty (see SecureFile)
+	dir  string
+	mu   sync.Mutex
+	keep bool
+}
+
+// NewFile creates a new temp file, just as os.CreateTemp, but it is removed on Close.
+func NewFile(dir, pattern string) (*File, error) {
+	fh, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &File{File: fh}, nil
+}
+
+// Keep the file - Close won't remove it.
+func (f *File) Keep() {
+	f.mu.Lock()
+	f.keep = true
+	f.mu.Unlock()
+}
+
+// Close the file, and remove it, if Keep hasn't been called.
+func (f *File) Close() error {
+	f.mu.Lock()
+	keep := f.keep
+	f.mu.Unlock()
+	err := f.File.Close()
+	if !keep {
+		if rmErr := os.Remove(f.File.Name()); rmErr != nil && err == nil && !errors.Is(rmErr, fs.ErrNotExist) {
+			err = rmErr
+		}
+		if f.dir != "" {
+			if rmErr := os.Remove(f.dir); rmErr != nil && err == nil && !errors.Is(rmErr, fs.ErrNotExist) {
+				err = rmErr
+			}
+		}
+	}
+	return err
+}
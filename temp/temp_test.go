@@ -0,0 +1,68 @@
+/*
+  Copyright 2023 Tamás Gulácsi
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package temp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFile(t *testing.T) {
+	dir := t.TempDir()
+	fh, err := NewFile(dir, "newfile-*.tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := fh.Name()
+	if filepath.Dir(name) != dir {
+		t.Errorf("%q is not in %q", name, dir)
+	}
+	if _, err := fh.WriteString("data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fh.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("%q still exists after Close: %+v", name, err)
+	}
+	if err := fh.Close(); err == nil {
+		t.Error("second Close succeeded")
+	}
+
+	if fh, err = NewFile(dir, "newfile-*.tmp"); err != nil {
+		t.Fatal(err)
+	}
+	name = fh.Name()
+	fh.Keep()
+	if _, err := fh.WriteString("kept"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fh.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(name); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "kept" {
+		t.Errorf("got %q, wanted %q", b, "kept")
+	}
+
+	if _, err := NewFile(filepath.Join(dir, "missing"), "newfile-*.tmp"); err == nil {
+		t.Error("NewFile in a missing directory succeeded")
+	}
+}